@@ -1,9 +1,16 @@
 package cades
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"golang.org/x/exp/slog"
 )
@@ -43,6 +50,12 @@ type CadesResponseData struct {
 	Type        string      `json:"type,omitempty"`
 	Value       interface{} `json:"value,omitempty"`
 	Message     string      `json:"message,omitempty"`
+
+	// ObjId is the id of the object returned by the request, zero if the
+	// response does not carry an object.
+	ObjId uint32 `json:"-"`
+	// Generation of the nmcades process that answered the request.
+	Generation uint32 `json:"-"`
 }
 
 func CadesDataFromAnswer(answer *CadesResponseBody) (*CadesResponseData, error) {
@@ -51,13 +64,27 @@ func CadesDataFromAnswer(answer *CadesResponseBody) (*CadesResponseData, error)
 		return &CadesResponseData{}, err
 	}
 
+	normalizeReturnValue(&data)
+	return &data, nil
+}
+
+func normalizeReturnValue(data *CadesResponseData) {
 	if value, ok := data.ReturnValue.Value.(float64); ok {
 		if intValue := int32(value); value != float64(intValue) {
 			data.ReturnValue.Value = intValue
 		}
 	}
+}
 
-	return &data, nil
+// responseFrame is a frame received from nmcades, either a response or a
+// callback. It is decoded once, straight from the received message.
+type responseFrame struct {
+	Tabid string `json:"tabid"`
+	Data  struct {
+		CadesResponseData
+		CallbackId *uint32 `json:"callback_id"`
+		Object     string  `json:"object"`
+	} `json:"data"`
 }
 
 type CadesRequestBody struct {
@@ -73,160 +100,647 @@ type CadesResponseBody struct {
 type CadesParam struct {
 	Type  string `json:"type"`
 	Value any    `json:"value"`
+
+	generation uint32
 }
 
+// Cades is a session with the nmcades process. It is safe for concurrent
+// use: requests are serialized and each response is matched to its request
+// by requestid.
 type Cades struct {
 	Id        string
 	RequestId uint32
 	ObjId     uint32
-	Process   *CadesProcess
+	Transport Transport
+	// Process is set when the session runs a local nmcades process.
+	Process *CadesProcess
+	// AutoRestart starts a new nmcades process and repeats the init
+	// handshake when the current one exits. The failed request still returns
+	// an error and objects of the old process become stale.
+	AutoRestart bool
+	// Generation counts restarts, objects remember the generation they were
+	// created in.
+	Generation uint32
+
+	originURL        string
+	handshakeTimeout time.Duration
+	log              *slog.Logger
+	recorder         *Recorder
+	startedAt        time.Time
+	requests         uint64
+	newTransport     func(ctx context.Context) (Transport, error)
+
+	closeMu     sync.Mutex
+	closed      bool
+	closeErr    error
+	interrupted *InterruptedError
+	lockOnce    sync.Once
+	lock        chan struct{}
+	// objectsMu guards objects and the changes of Generation, so they can be
+	// read without the session lock, e.g. by a callback handler.
+	objectsMu     sync.Mutex
+	objects       map[uint32]struct{}
+	callbacksOnce sync.Once
+	callbacks     *CallbackRegistry
+
+	// parent and scope are set for a scoped view of the session, see NewScope.
+	// ctx replaces the context of requests sent through the view.
+	ctx    context.Context
+	parent *Cades
+	scope  *Scope
+}
+
+// InterruptedError is returned by the requests of a session whose transport
+// was closed because the context of an earlier request was done. It matches
+// ErrCadesClosed with errors.Is, Err is the error of that context.
+type InterruptedError struct {
+	Err error
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("%s: transport closed by an interrupted request: %s", ErrCadesClosed, e.Err)
+}
+
+func (e *InterruptedError) Is(target error) bool {
+	return target == ErrCadesClosed
+}
+
+// TransportError is returned when a request could not be sent or its
+// response could not be read, the session may be out of sync after it.
+type TransportError struct {
+	Op  string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("[nmcades] %s: %s", e.Op, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
 }
 
 type CadesObject struct {
-	Cades *Cades
-	ObjId uint32
+	Cades      *Cades
+	ObjId      uint32
+	Generation uint32
 }
 
-func NewCades() (*Cades, error) {
-	process, err := NewNMCadesProcess()
+func (c *CadesObject) cadesObject() *CadesObject {
+	return c
+}
+
+func NewCades(opts ...Option) (*Cades, error) {
+	return NewCadesContext(context.Background(), opts...)
+}
+
+func NewCadesContext(ctx context.Context, opts ...Option) (*Cades, error) {
+	options := newCadesOptions(opts)
+	process, err := NewNMCadesProcessWithConfig(options.process)
 	if err != nil {
 		return &Cades{}, err
 	}
 
-	cades := Cades{
-		Id:        "CadesAgent",
-		RequestId: 0,
-		ObjId:     0,
-		Process:   process,
+	cades, err := newCades(ctx, process, options)
+	err = process.attachStderr(err, 0)
+	cades.Process = process
+	cades.newTransport = func(ctx context.Context) (Transport, error) {
+		return NewNMCadesProcessWithConfig(options.process)
+	}
+	return cades, err
+}
+
+// NewCadesWithTransport starts a session over an already established
+// transport, e.g. a remote nmcades or a fake one in tests. Options of the
+// local process are ignored.
+func NewCadesWithTransport(ctx context.Context, transport Transport, opts ...Option) (*Cades, error) {
+	return newCades(ctx, transport, newCadesOptions(opts))
+}
+
+func newCades(ctx context.Context, transport Transport, options *cadesOptions) (*Cades, error) {
+	if framed, ok := transport.(framedTransport); ok && options.process.MaxMessageSize > 0 {
+		framed.framer().MaxMessageSize = options.process.MaxMessageSize
+	}
+
+	cades := &Cades{
+		Id:               options.tabId,
+		RequestId:        0,
+		ObjId:            0,
+		Transport:        transport,
+		AutoRestart:      options.autoRestart,
+		originURL:        options.originURL,
+		handshakeTimeout: options.handshakeTimeout,
+		log:              options.logger,
+		recorder:         options.recorder,
+		callbacks:        newCallbackRegistry(options.originURL, options.enableInternalCSP),
+	}
+
+	unlock, err := cades.acquire(ctx)
+	if err != nil {
+		return cades, err
+	}
+	defer unlock()
+
+	if err := cades.handshake(ctx); err != nil {
+		return cades, err
+	}
+
+	return cades, nil
+}
+
+func (cades *Cades) handshake(ctx context.Context) error {
+	if cades.handshakeTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cades.handshakeTimeout)
+		defer cancel()
+	}
+
+	url := cades.originURL
+	if url == "" {
+		url = defaultOriginURL
 	}
 
 	body := &CadesRequestBody{
-		Tabid: cades.Id,
 		Data: &CadesRequestData{
-			RequestId:   cades.RequestId,
 			Destination: "nmcades",
 			Type:        "init",
-			Url:         "http://localhost:42217/cades",
+			Url:         url,
 		},
 	}
 
-	message, err := json.Marshal(body)
-	if err != nil {
-		return &Cades{}, err
+	if _, err := cades.roundTrip(ctx, body); err != nil {
+		return err
+	}
+
+	cades.startedAt = time.Now()
+	return nil
+}
+
+func (cades *Cades) logger() *slog.Logger {
+	if cades.parent != nil {
+		return cades.parent.logger()
+	}
+	if cades.log != nil {
+		return cades.log
+	}
+	return slog.Default()
+}
+
+// Restart replaces the nmcades process and repeats the init handshake.
+// Objects created before the restart become stale.
+func (cades *Cades) Restart(ctx context.Context) error {
+	if cades.parent != nil {
+		return cades.parent.Restart(ctx)
 	}
 
-	answer, err := cades.sendRequestToProcess(message)
+	unlock, err := cades.acquire(ctx)
 	if err != nil {
-		return &cades, err
+		return err
+	}
+	defer unlock()
+
+	return cades.restart(ctx)
+}
+
+func (cades *Cades) restart(ctx context.Context) error {
+	if cades.newTransport == nil {
+		return errors.New("restart is not supported by the transport")
+	}
+	if cades.isClosed() {
+		return ErrCadesClosed
 	}
 
-	data, err := CadesDataFromAnswer(answer)
+	// closeMu is held only to swap the transport: Close must not wait for
+	// the new process, and callbacks of the handshake may send requests
+	if err := cades.Transport.Close(); err != nil {
+		cades.logger().Debug(fmt.Sprintf("[Cades.restart] Close previous process: %s", err))
+	}
+	transport, err := cades.newTransport(ctx)
 	if err != nil {
-		return &cades, err
+		return err
+	}
+
+	cades.closeMu.Lock()
+	if cades.closed {
+		cades.closeMu.Unlock()
+		transport.Close()
+		return ErrCadesClosed
+	}
+	cades.Transport = transport
+	cades.interrupted = nil
+	cades.closeMu.Unlock()
+
+	cades.logger().Debug(fmt.Sprintf("[Cades.restart] Start generation %d", cades.Generation+1))
+	if process, ok := transport.(*CadesProcess); ok {
+		cades.Process = process
+	}
+	cades.objectsMu.Lock()
+	cades.Generation++
+	cades.objects = nil
+	cades.objectsMu.Unlock()
+	cades.RequestId = 0
+	cades.ObjId = 0
+	cades.requests = 0
+
+	return cades.handshake(ctx)
+}
+
+// Close stops the session and waits for nmcades to exit, see
+// CadesProcess.Close. It may be called more than once and from several
+// goroutines. For a scoped view it releases the objects of the scope and
+// leaves the session open.
+func (cades *Cades) Close() error {
+	if cades.scope != nil {
+		return cades.scope.Release()
+	}
+
+	cades.closeMu.Lock()
+	defer cades.closeMu.Unlock()
+	if cades.closed {
+		return cades.closeErr
+	}
+
+	cades.closed = true
+	if cades.Transport != nil {
+		cades.closeErr = cades.Transport.Close()
+	}
+	return cades.closeErr
+}
+
+func (cades *Cades) isClosed() bool {
+	cades.closeMu.Lock()
+	defer cades.closeMu.Unlock()
+	return cades.closed
+}
+
+// closedError returns *InterruptedError for a session whose transport was
+// closed by closeOnDone and ErrCadesClosed for a closed session.
+func (cades *Cades) closedError() error {
+	cades.closeMu.Lock()
+	defer cades.closeMu.Unlock()
+	if cades.interrupted != nil {
+		return cades.interrupted
+	}
+	if cades.closed {
+		return ErrCadesClosed
+	}
+	return nil
+}
+
+// interrupt marks the session interrupted. A session that cannot start a new
+// transport, such as a session of Mux, is closed for good.
+func (cades *Cades) interrupt(err error) {
+	cades.closeMu.Lock()
+	defer cades.closeMu.Unlock()
+	cades.interrupted = &InterruptedError{Err: err}
+	if cades.newTransport == nil {
+		cades.closed = true
+	}
+}
+
+// sessionLockKey marks a context whose goroutine holds the session lock, so
+// callback handlers can send nested requests.
+type sessionLockKey struct{}
 
-	} else if strings.ToLower(data.Type) == "error" {
-		return &cades, fmt.Errorf("[nmcades] %s", data.Message)
+// acquire takes the session lock unless ctx comes from a callback handler
+// that already holds it.
+func (cades *Cades) acquire(ctx context.Context) (unlock func(), err error) {
+	if held, _ := ctx.Value(sessionLockKey{}).(*Cades); held == cades {
+		return func() {}, nil
 	}
 
-	return &cades, nil
+	cades.lockOnce.Do(func() {
+		cades.lock = make(chan struct{}, 1)
+	})
+
+	select {
+	case cades.lock <- struct{}{}:
+		return func() { <-cades.lock }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
 }
 
-func (cades *Cades) Close() {
-	cades.Process.Cmd.Process.Kill()
+// closeOnDone closes the transport, killing a local nmcades, when ctx is done
+// before stop is called. This unblocks a pending read of the response. stop
+// waits for the watcher and reports whether it closed the transport.
+func (cades *Cades) closeOnDone(ctx context.Context) (stop func() bool) {
+	if ctx.Done() == nil {
+		return func() bool { return false }
+	}
+
+	transport := cades.Transport
+	done := make(chan struct{})
+	closed := make(chan bool, 1)
+	go func() {
+		select {
+		case <-ctx.Done():
+			cades.logger().Debug(fmt.Sprintf("[Cades.send] Close transport: %s", ctx.Err()))
+			if killer, ok := transport.(interface{ Kill() error }); ok {
+				killer.Kill()
+			} else {
+				transport.Close()
+			}
+			closed <- true
+		case <-done:
+			closed <- false
+		}
+	}()
+	return func() bool {
+		close(done)
+		return <-closed
+	}
 }
 
-func (cades *Cades) handlerCallback(answer *CadesResponseBody) (*CadesResponseBody, error) {
-	slog.Debug("[Cades.HandlerCallback -> receive callback]")
-	var callback CallbackData
-	err := json.Unmarshal(*answer.Data, &callback)
+// handlerCallback must be called with the session lock held. The handler
+// gets a context that lets it send nested requests through the session.
+func (cades *Cades) handlerCallback(ctx context.Context, callback *CallbackData, requestId uint32) error {
+	cades.logger().Debug("[Cades.HandlerCallback -> receive callback]")
+
+	handler := cades.callbackRegistry().lookup(callback)
+	result, err := handler(context.WithValue(ctx, sessionLockKey{}, cades), cades, callback)
 	if err != nil {
-		slog.Debug(fmt.Sprintf("[Cades.HandlerCallback] Fail to parse json: %s", err))
-		return answer, err
+		return err
 	}
 
 	body := &CadesRequestBody{
 		Tabid: cades.Id,
 		Data: &CadesRequestData{
+			CallbackId:  callback.Id,
 			ObjId:       cades.ObjId,
 			Destination: "nmcades",
-			Type:        "result",
+			Type:        result.Type,
+			Value:       result.Value,
+			Params:      result.Params,
 		},
 	}
+	if body.Data.Type == "" {
+		body.Data.Type = "result"
+	}
+	if body.Data.Value == "" {
+		body.Data.Value = callback.Value
+	}
 
-	body.Data.CallbackId = callback.Id
-	if callback.Type == "callback" {
-		switch callback.Value {
-		case "result = cadesplugin.EnableInternalCSP":
-			body.Data.Value = "result = cadesplugin.EnableInternalCSP"
-			body.Data.Params = []CadesParam{
-				{Type: "boolean", Value: false},
-			}
+	return cades.send(body, requestId)
+}
 
-		case "result = window.document.URL":
-			body.Data.Value = "result = window.document.URL"
-			body.Data.Params = []CadesParam{
-				{Type: "string", Value: "http://localhost:42217/cades"},
-			}
+// send writes the request to the transport. Transports that implement
+// FuncSender get the JSON encoded straight into the frame, so large params
+// are not copied into an intermediate buffer. Recording needs the whole
+// message and falls back to Send.
+func (cades *Cades) send(request *CadesRequestBody, requestId uint32) error {
+	sender, ok := cades.Transport.(FuncSender)
+	if !ok || cades.recorder != nil {
+		var buf bytes.Buffer
+		if err := writeRequest(&buf, request); err != nil {
+			return err
 		}
 
-	} else if callback.Type == "approved_site" {
-		body.Data.Type = "approved_site"
-		body.Data.Value = "is_approved_site: http://localhost:42217/cades"
-		body.Data.Params = []CadesParam{
-			{Type: "boolean", Value: true},
+		message := buf.Bytes()
+		cades.debugMessage("[Cades.send] Send message", message)
+		cades.record(RecordSend, requestId, message)
+		return cades.Transport.Send(message)
+	}
+
+	var counter countingWriter
+	if err := writeRequest(&counter, request); err != nil {
+		return err
+	}
+
+	if cades.logger().Enabled(context.Background(), slog.LevelDebug) {
+		var prefix limitWriter
+		if err := writeRequest(&prefix, request); err != nil && err != errLogLimit {
+			return err
 		}
-	} else {
-		return answer, ErrUnknownCallback
+		cades.debugMessage("[Cades.send] Send message", prefix.buf)
 	}
 
-	message, err := json.Marshal(body)
-	if err != nil {
-		return answer, err
+	return sender.SendFunc(counter.n, func(w io.Writer) error {
+		return writeRequest(w, request)
+	})
+}
+
+// debugMessage logs the message if debug logging is enabled. Messages longer
+// than maxLoggedMessage are cut.
+func (cades *Cades) debugMessage(text string, message []byte) {
+	logger := cades.logger()
+	if !logger.Enabled(context.Background(), slog.LevelDebug) {
+		return
 	}
 
-	return cades.sendRequestToProcess(message)
+	if len(message) > maxLoggedMessage {
+		logger.Debug(fmt.Sprintf("%s: %s... (%d bytes)", text, message[:maxLoggedMessage], len(message)))
+		return
+	}
+	logger.Debug(fmt.Sprintf("%s: %s", text, message))
 }
 
 func (cades *Cades) SendRequest(request *CadesRequestBody) (*CadesResponseData, error) {
-	message, err := json.Marshal(request)
+	return cades.SendRequestContext(context.Background(), request)
+}
+
+// SendRequestContext sends the request and waits for the response. If ctx is
+// done before the response arrives, the transport is closed and ctx.Err() is
+// returned. With AutoRestart a new nmcades process is started at once,
+// otherwise later requests fail with *InterruptedError.
+func (cades *Cades) SendRequestContext(ctx context.Context, request *CadesRequestBody) (*CadesResponseData, error) {
+	return cades.sendRequest(ctx, request, nil)
+}
+
+// sendRequest checks that obj and the object params of the request belong to
+// the current nmcades process before sending it.
+func (cades *Cades) sendRequest(ctx context.Context, request *CadesRequestBody, obj *CadesObject) (*CadesResponseData, error) {
+	if cades.parent != nil {
+		if cades.ctx != nil {
+			ctx = cades.ctx
+		}
+		data, err := cades.parent.sendRequest(ctx, request, obj)
+		if err == nil && data.ObjId != 0 {
+			cades.scope.add(data.ObjId, data.Generation)
+		}
+		return data, err
+	}
+
+	unlock, err := cades.acquire(ctx)
 	if err != nil {
 		return &CadesResponseData{}, err
 	}
+	defer unlock()
 
-	answer, err := cades.sendRequestToProcess(message)
-	if err != nil {
+	if err := cades.closedError(); err != nil {
 		return &CadesResponseData{}, err
 	}
+	if obj != nil && obj.Generation != cades.Generation {
+		return &CadesResponseData{}, fmt.Errorf("%w: objid %d", ErrStaleObject, obj.ObjId)
+	}
+	for _, param := range request.Data.Params {
+		if param.Type == "object" && param.generation != cades.Generation {
+			return &CadesResponseData{}, fmt.Errorf("%w: objid %v", ErrStaleObject, param.Value)
+		}
+	}
+
+	process := cades.Process
+	mark := process.stderrMark()
+	data, err := cades.roundTrip(ctx, request)
+	err = process.attachStderr(err, mark)
+	if err != nil && cades.AutoRestart && cades.needsRestart(err) {
+		// the context of an interrupted request is done, the handshake of
+		// the new process is bounded by the handshake timeout only
+		restartCtx := ctx
+		if ctx.Err() != nil {
+			restartCtx = context.Background()
+		}
+		if restartErr := cades.restart(restartCtx); restartErr != nil {
+			return data, fmt.Errorf("%w; restart failed: %s", err, restartErr)
+		}
+	}
+	return data, err
+}
+
+// needsRestart must be called with the session lock held.
+func (cades *Cades) needsRestart(err error) bool {
+	if errors.Is(err, ErrProcessExited) {
+		return true
+	}
+
+	var interrupted *InterruptedError
+	return cades.newTransport != nil && errors.As(cades.closedError(), &interrupted)
+}
+
+// roundTrip must be called with the session lock held.
+func (cades *Cades) roundTrip(ctx context.Context, request *CadesRequestBody) (*CadesResponseData, error) {
+	request.Tabid = cades.Id
+	request.Data.RequestId = cades.RequestId
+	cades.RequestId++
 
-	data, err := CadesDataFromAnswer(answer)
+	stop := cades.closeOnDone(ctx)
+	data, err := cades.sendRequestToProcess(ctx, request)
+	if stop() {
+		cades.interrupt(ctx.Err())
+		return &CadesResponseData{}, ctx.Err()
+	}
 	if err != nil {
+		// the transport of a pending request was closed by Close
+		if closedErr := cades.closedError(); closedErr != nil {
+			return &CadesResponseData{}, closedErr
+		}
 		return &CadesResponseData{}, err
+	}
+
+	cades.requests++
+	if strings.ToLower(data.Type) == "error" {
+		return &CadesResponseData{}, newNmcadesError(request.Data, data.Message)
+	}
+
+	data.Generation = cades.Generation
+	if data.ReturnValue.Type == "object" {
+		data.ObjId = cades.nextObjId(data.ReturnValue.Value)
+	}
 
-	} else if strings.ToLower(data.Type) == "error" {
-		return &CadesResponseData{}, fmt.Errorf("[nmcades] %s", data.Message)
+	cades.objectsMu.Lock()
+	if data.ReturnValue.Type == "object" {
+		if cades.objects == nil {
+			cades.objects = make(map[uint32]struct{})
+		}
+		cades.objects[data.ObjId] = struct{}{}
+	}
+	if request.Data.Type == "release" {
+		delete(cades.objects, request.Data.ObjId)
 	}
+	cades.objectsMu.Unlock()
 
 	return data, nil
 }
 
-func (cades *Cades) sendRequestToProcess(request []byte) (*CadesResponseBody, error) {
+// nextObjId returns the object id reported by nmcades in retval. Plugin
+// versions that do not report it number objects sequentially, so the
+// counter is used as a fallback and as a consistency check.
+func (cades *Cades) nextObjId(value any) uint32 {
+	expected := cades.ObjId + 1
+	id, ok := objIdFromValue(value)
+	if !ok {
+		cades.ObjId = expected
+		return expected
+	}
 
-	slog.Debug(fmt.Sprintf("[Cades.send] Send message: %s", string(request)))
-	PostMessage(*cades.Process.Stdin, request)
+	if id != expected {
+		cades.logger().Warn(fmt.Sprintf("[Cades.send] nmcades returned objid %d, expected %d", id, expected))
+	}
+	if id > cades.ObjId {
+		cades.ObjId = id
+	}
+	return id
+}
 
-	message := GetMessage(*cades.Process.Stdout)
-	slog.Debug(fmt.Sprintf("[Cades.send] Receive message: %s", message))
+func objIdFromValue(value any) (uint32, bool) {
+	switch v := value.(type) {
+	case float64:
+		if v > 0 && v == float64(uint32(v)) {
+			return uint32(v), true
+		}
+	case int32:
+		if v > 0 {
+			return uint32(v), true
+		}
+	case string:
+		if id, err := strconv.ParseUint(v, 10, 32); err == nil && id > 0 {
+			return uint32(id), true
+		}
+	}
+	return 0, false
+}
 
-	var answer CadesResponseBody
-	if err := json.Unmarshal([]byte(message), &answer); err != nil {
-		slog.Debug(fmt.Sprintf("[Cades.send] Fail to parse json: %s", err))
-		return &answer, err
+// sendRequestToProcess must be called with the session lock held. It answers the
+// callbacks nmcades sends while processing the request and skips frames
+// that belong to other requests.
+func (cades *Cades) sendRequestToProcess(ctx context.Context, request *CadesRequestBody) (*CadesResponseData, error) {
+	requestId := request.Data.RequestId
+	if err := cades.send(request, requestId); err != nil {
+		cades.logger().Debug(fmt.Sprintf("[Cades.send] Fail to send message: %s", err))
+		return &CadesResponseData{}, &TransportError{Op: "send request", Err: err}
 	}
 
-	if strings.Contains(message, "callback_id") {
-		return cades.handlerCallback(&answer)
+	for {
+		message, err := cades.Transport.Receive()
+		if err != nil {
+			cades.logger().Debug(fmt.Sprintf("[Cades.send] Fail to receive message: %s", err))
+			return &CadesResponseData{}, &TransportError{Op: "receive response", Err: err}
+		}
+		cades.debugMessage("[Cades.send] Receive message", message)
+		cades.record(RecordReceive, requestId, message)
+
+		var frame responseFrame
+		if err := json.Unmarshal(message, &frame); err != nil {
+			cades.logger().Debug(fmt.Sprintf("[Cades.send] Fail to parse json: %s", err))
+			return &CadesResponseData{}, &TransportError{Op: "parse response", Err: err}
+		}
+
+		if frame.Data.CallbackId != nil {
+			callback := &CallbackData{
+				Id:     *frame.Data.CallbackId,
+				Object: frame.Data.Object,
+				Type:   frame.Data.Type,
+			}
+			callback.Value, _ = frame.Data.Value.(string)
+			if err := cades.handlerCallback(ctx, callback, requestId); err != nil {
+				return &CadesResponseData{}, err
+			}
+			continue
+		}
+
+		if id := frame.Data.RequestId; id != 0 && id != requestId {
+			cades.logger().Debug(fmt.Sprintf("[Cades.send] Skip response to request %d, expected %d", id, requestId))
+			continue
+		}
+
+		data := frame.Data.CadesResponseData
+		normalizeReturnValue(&data)
+		return &data, nil
 	}
+}
 
-	cades.RequestId++
-	return &answer, nil
+func (cades *Cades) record(direction string, requestId uint32, message []byte) {
+	if cades.recorder != nil {
+		cades.recorder.record(direction, requestId, message)
+	}
 }
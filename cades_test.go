@@ -0,0 +1,212 @@
+package cades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newEmulatorCades starts a session over an emulator with the given classes.
+func newEmulatorCades(t *testing.T, classes ...*EmulatorClass) (*Cades, *Emulator) {
+	t.Helper()
+
+	emulator := NewEmulator()
+	for _, class := range classes {
+		emulator.Register(class)
+	}
+
+	cades, err := NewCadesWithTransport(context.Background(), emulator)
+	if err != nil {
+		t.Fatalf("NewCadesWithTransport: %s", err)
+	}
+	t.Cleanup(func() { cades.Close() })
+	return cades, emulator
+}
+
+var testObjectClass = &EmulatorClass{
+	Name:       "Test.Object",
+	Properties: map[string]any{"Value": ""},
+}
+
+// TestConcurrentRequests shares one session between goroutines, run it with
+// -race. Every object gets a value of its own, a mixed up response or
+// object id shows up as a wrong value.
+func TestConcurrentRequests(t *testing.T) {
+	cades, _ := newEmulatorCades(t, testObjectClass)
+
+	const (
+		goroutines = 32
+		iterations = 20
+	)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, goroutines)
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				if err := roundTripObject(cades, fmt.Sprintf("%d-%d", i, j)); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Error(err)
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() = %d, want 0", live)
+	}
+	if cades.RequestId != goroutines*iterations*4+1 {
+		t.Errorf("RequestId = %d, want %d", cades.RequestId, goroutines*iterations*4+1)
+	}
+}
+
+func roundTripObject(cades *Cades, value string) error {
+	obj, err := NewDispatchObject(cades, "Test.Object")
+	if err != nil {
+		return err
+	}
+	if err := obj.Set("Value", value); err != nil {
+		return err
+	}
+
+	got, err := obj.Get("Value")
+	if err != nil {
+		return err
+	}
+	if got != value {
+		return fmt.Errorf("objid %d: Value = %v, want %s", obj.ObjId, got, value)
+	}
+	return obj.Release()
+}
+
+// newBlockingCades returns a session whose Test.Object has a method Wait
+// that does not answer until the test ends.
+func newBlockingCades(t *testing.T) *Cades {
+	t.Helper()
+
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	blockingClass := &EmulatorClass{
+		Name:       "Test.Object",
+		Properties: map[string]any{"Value": ""},
+		Methods: map[string]EmulatorMethod{
+			"Wait": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				<-release
+				return nil, nil
+			},
+		},
+	}
+
+	cades, _ := newEmulatorCades(t, blockingClass)
+	return cades
+}
+
+func TestContextInterruptsSession(t *testing.T) {
+	cades := newBlockingCades(t)
+	obj, err := NewDispatchObject(cades, "Test.Object")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := obj.CallContext(ctx, "Wait"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CallContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	_, err = obj.Get("Value")
+	var interrupted *InterruptedError
+	if !errors.Is(err, ErrCadesClosed) || !errors.As(err, &interrupted) {
+		t.Fatalf("Get() after an interrupted request: error = %v, want *InterruptedError", err)
+	}
+	if interrupted.Err != context.DeadlineExceeded {
+		t.Errorf("InterruptedError.Err = %v, want %v", interrupted.Err, context.DeadlineExceeded)
+	}
+}
+
+func TestContextRestartsSession(t *testing.T) {
+	cades := newBlockingCades(t)
+	cades.AutoRestart = true
+	cades.newTransport = func(ctx context.Context) (Transport, error) {
+		emulator := NewEmulator()
+		emulator.Register(testObjectClass)
+		return emulator, nil
+	}
+
+	obj, err := NewDispatchObject(cades, "Test.Object")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := obj.CallContext(ctx, "Wait"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CallContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	if cades.Generation != 1 {
+		t.Errorf("Generation = %d, want 1", cades.Generation)
+	}
+	if _, err := obj.Get("Value"); !errors.Is(err, ErrStaleObject) {
+		t.Errorf("Get() on an object of the killed process: error = %v, want %v", err, ErrStaleObject)
+	}
+	if err := roundTripObject(cades, "restarted"); err != nil {
+		t.Errorf("request after restart: %s", err)
+	}
+}
+
+// TestCloseDuringRestart closes the session while a callback of the init
+// handshake of the new process is being answered with a nested request.
+func TestCloseDuringRestart(t *testing.T) {
+	cades, _ := newEmulatorCades(t, testObjectClass)
+	cades.newTransport = func(ctx context.Context) (Transport, error) {
+		emulator := NewEmulator()
+		emulator.Register(testObjectClass)
+		emulator.InitCallbacks = []CallbackData{{Type: "callback", Value: "Test.Block"}}
+		return emulator, nil
+	}
+
+	entered := make(chan error, 1)
+	release := make(chan struct{})
+	cades.HandleCallback("Test.Block", func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		_, err := CreateObjectContext(ctx, cades, "Test.Object")
+		entered <- err
+		<-release
+		return &CallbackResult{}, nil
+	})
+
+	restarted := make(chan error, 1)
+	go func() { restarted <- cades.Restart(context.Background()) }()
+
+	select {
+	case err := <-entered:
+		if err != nil {
+			t.Errorf("nested request during the handshake: %s", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("nested request during the handshake is blocked")
+	}
+
+	closed := make(chan error, 1)
+	go func() { closed <- cades.Close() }()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close is blocked by the handshake")
+	}
+
+	close(release)
+	if err := <-restarted; err == nil {
+		t.Error("Restart() of a closed session: error = nil")
+	}
+}
@@ -0,0 +1,150 @@
+package cades
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Callback keys. Callbacks of type "callback" are matched by their value,
+// other callbacks by their type.
+const (
+	CallbackEnableInternalCSP = "result = cadesplugin.EnableInternalCSP"
+	CallbackDocumentURL       = "result = window.document.URL"
+	CallbackApprovedSite      = "approved_site"
+	CallbackConfirm           = "result = window.confirm"
+	CallbackPrompt            = "result = window.prompt"
+)
+
+const defaultOriginURL = "http://localhost:42217/cades"
+
+// CallbackResult is sent back to nmcades as the answer to a callback. Empty
+// Type and Value default to "result" and the value of the callback.
+type CallbackResult struct {
+	Type   string
+	Value  string
+	Params []CadesParam
+}
+
+// CallbackHandler answers a callback. ctx may be passed to the *Context
+// functions to send nested requests while the callback is being answered.
+type CallbackHandler func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error)
+
+// CallbackRegistry maps callback keys to handlers. A handler is registered
+// for a key prefix, the longest matching prefix wins.
+type CallbackRegistry struct {
+	mu       sync.RWMutex
+	handlers map[string]CallbackHandler
+	unknown  CallbackHandler
+}
+
+func NewCallbackRegistry() *CallbackRegistry {
+	return newCallbackRegistry(defaultOriginURL, false)
+}
+
+func newCallbackRegistry(originURL string, enableInternalCSP bool) *CallbackRegistry {
+	registry := &CallbackRegistry{
+		handlers: make(map[string]CallbackHandler),
+		unknown:  UnknownCallbackHandler,
+	}
+	registry.Handle(CallbackEnableInternalCSP, BoolCallbackHandler(enableInternalCSP))
+	registry.Handle(CallbackDocumentURL, StringCallbackHandler(originURL))
+	registry.Handle(CallbackApprovedSite, ApproveSiteCallbackHandler(originURL, true))
+	registry.Handle(CallbackConfirm, BoolCallbackHandler(false))
+	registry.Handle(CallbackPrompt, StringCallbackHandler(""))
+	return registry
+}
+
+func (registry *CallbackRegistry) Handle(key string, handler CallbackHandler) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.handlers[key] = handler
+}
+
+// HandleUnknown sets the handler for callbacks without a registered key.
+func (registry *CallbackRegistry) HandleUnknown(handler CallbackHandler) {
+	registry.mu.Lock()
+	defer registry.mu.Unlock()
+	registry.unknown = handler
+}
+
+func (registry *CallbackRegistry) lookup(callback *CallbackData) CallbackHandler {
+	key := callback.Type
+	if callback.Type == "callback" {
+		key = callback.Value
+	}
+
+	registry.mu.RLock()
+	defer registry.mu.RUnlock()
+
+	var (
+		handler CallbackHandler
+		matched = -1
+	)
+	for prefix, h := range registry.handlers {
+		if len(prefix) > matched && strings.HasPrefix(key, prefix) {
+			handler = h
+			matched = len(prefix)
+		}
+	}
+
+	if handler == nil {
+		return registry.unknown
+	}
+	return handler
+}
+
+func (cades *Cades) callbackRegistry() *CallbackRegistry {
+	if cades.parent != nil {
+		return cades.parent.callbackRegistry()
+	}
+
+	cades.callbacksOnce.Do(func() {
+		if cades.callbacks == nil {
+			cades.callbacks = NewCallbackRegistry()
+		}
+	})
+	return cades.callbacks
+}
+
+// HandleCallback overrides the handler of the callbacks matching key.
+func (cades *Cades) HandleCallback(key string, handler CallbackHandler) {
+	cades.callbackRegistry().Handle(key, handler)
+}
+
+func (cades *Cades) HandleUnknownCallback(handler CallbackHandler) {
+	cades.callbackRegistry().HandleUnknown(handler)
+}
+
+func BoolCallbackHandler(value bool) CallbackHandler {
+	return func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		return &CallbackResult{Params: []CadesParam{{Type: "boolean", Value: value}}}, nil
+	}
+}
+
+func StringCallbackHandler(value string) CallbackHandler {
+	return func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		return &CallbackResult{Params: []CadesParam{{Type: "string", Value: value}}}, nil
+	}
+}
+
+// ApproveSiteCallbackHandler answers the site approval request of nmcades
+// for the origin url.
+func ApproveSiteCallbackHandler(url string, approved bool) CallbackHandler {
+	return func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		return &CallbackResult{
+			Type:   "approved_site",
+			Value:  "is_approved_site: " + url,
+			Params: []CadesParam{{Type: "boolean", Value: approved}},
+		}, nil
+	}
+}
+
+// UnknownCallbackHandler answers unknown callbacks of type "callback" with an
+// empty result and fails on other types with ErrUnknownCallback.
+func UnknownCallbackHandler(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+	if callback.Type == "callback" {
+		return &CallbackResult{}, nil
+	}
+	return &CallbackResult{}, ErrUnknownCallback
+}
@@ -1,6 +1,7 @@
 package cades
 
 import (
+	"crypto/x509"
 	"encoding/json"
 	"log"
 	"time"
@@ -8,6 +9,14 @@ import (
 
 type Certificate CadesObject
 
+func (certificate *Certificate) Release() error {
+	return ReleaseObject((*CadesObject)(certificate))
+}
+
+func (certificate *Certificate) cadesObject() *CadesObject {
+	return (*CadesObject)(certificate)
+}
+
 type ValidExport struct {
 	From    string `json:"from"`
 	To      string `json:"to"`
@@ -31,30 +40,12 @@ type CertificateExport struct {
 }
 
 func NewCertificate(cades *Cades) (*Certificate, error) {
-	body := &CadesRequestBody{
-		Tabid: cades.Id,
-		Data: &CadesRequestData{
-			RequestId:   cades.RequestId,
-			Destination: "nmcades",
-			Method:      "CreateObject",
-			Params: []CadesParam{
-				{Type: "string", Value: "CAdESCOM.Certificate"},
-			},
-		},
-	}
-
-	_, err := cades.SendRequest(body)
-
+	obj, err := CreateObject(cades, "CAdESCOM.Certificate")
 	if err != nil {
 		return &Certificate{}, err
 	}
 
-	cades.ObjId++
-	certificate := Certificate{
-		Cades: cades,
-		ObjId: cades.ObjId,
-	}
-	return &certificate, nil
+	return (*Certificate)(obj), nil
 }
 
 func (certificate *Certificate) Import(data string) error {
@@ -88,6 +79,47 @@ func (certificate *Certificate) PrivateKey() (*PrivateKey, error) {
 	return &pk, nil
 }
 
+// Export returns the certificate encoded with CADESCOM_ENCODE_BASE64 or
+// CADESCOM_ENCODE_BINARY.
+func (certificate *Certificate) Export(encodingType int) (string, error) {
+	param := ValueToParam(encodingType)
+	data, err := CallMethod((*CadesObject)(certificate), "Export", []CadesParam{*param})
+	if err != nil {
+		return "", err
+	}
+
+	if value, ok := data.ReturnValue.Value.(string); ok {
+		return value, nil
+	}
+
+	return "", ErrEmpty
+}
+
+// X509 exports the certificate and parses it.
+func (certificate *Certificate) X509() (*x509.Certificate, error) {
+	value, err := certificate.Export(CADESCOM_ENCODE_BASE64)
+	if err != nil {
+		return &x509.Certificate{}, err
+	}
+
+	data, err := decodeBase64(value)
+	if err != nil {
+		return &x509.Certificate{}, err
+	}
+
+	return LoadCertificate(data)
+}
+
+// IsValid builds and checks the certificate chain.
+func (certificate *Certificate) IsValid() (*CertificateStatus, error) {
+	obj, err := CallMethodWithObject((*CadesObject)(certificate), "IsValid", []CadesParam{})
+	if err != nil {
+		return &CertificateStatus{}, err
+	}
+
+	return (*CertificateStatus)(obj), nil
+}
+
 func (certificate *Certificate) Thumbprint() (string, error) {
 	return GetProperty[string]((*CadesObject)(certificate), "Thumbprint")
 }
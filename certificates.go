@@ -1,8 +1,13 @@
 package cades
 
-type Certificates struct {
-	Cades *Cades
-	ObjId uint32
+type Certificates CadesObject
+
+func (certificates *Certificates) Release() error {
+	return ReleaseObject((*CadesObject)(certificates))
+}
+
+func (certificates *Certificates) cadesObject() *CadesObject {
+	return (*CadesObject)(certificates)
 }
 
 func (certificates *Certificates) Count() (uint16, error) {
@@ -15,32 +20,22 @@ func (certificates *Certificates) Count() (uint16, error) {
 
 func (certificates *Certificates) Item(index uint16) (*Certificate, error) {
 	param := ValueToParam(index)
-	_, err := CallMethod((*CadesObject)(certificates), "Item", []CadesParam{*param})
+	obj, err := CallMethodWithObject((*CadesObject)(certificates), "Item", []CadesParam{*param})
 	if err != nil {
 		return &Certificate{}, err
 	}
 
-	certificates.Cades.ObjId++
-	certificate := Certificate{
-		Cades: certificates.Cades,
-		ObjId: certificates.Cades.ObjId,
-	}
-	return &certificate, nil
+	return (*Certificate)(obj), nil
 }
 
 // Arguments: (FindType, varCriteria, bFindValidOnly)
 // https://learn.microsoft.com/en-us/windows/win32/seccrypto/certificates-find
 func (certificates *Certificates) Find(args ...any) (*Certificates, error) {
 	params := ArgumentsToParams(3, args)
-	_, err := CallMethod((*CadesObject)(certificates), "Find", params)
+	obj, err := CallMethodWithObject((*CadesObject)(certificates), "Find", params)
 	if err != nil {
 		return &Certificates{}, err
 	}
 
-	certificates.Cades.ObjId++
-	newCertificates := Certificates{
-		Cades: certificates.Cades,
-		ObjId: certificates.Cades.ObjId,
-	}
-	return &newCertificates, nil
+	return (*Certificates)(obj), nil
 }
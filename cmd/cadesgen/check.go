@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// declarations maps "Type.Method" and "Func" to a signature without
+// parameter names.
+type declarations map[string]string
+
+// checkHandwritten compares the methods of the hand-written wrappers with
+// the methods the generator would emit for them and checks that the custom
+// methods of the generated classes are written by hand.
+func checkHandwritten(spec *Spec, generated []byte, dir string, skip string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "generated.go", generated, 0)
+	if err != nil {
+		return err
+	}
+	expected := collect(fset, file)
+
+	actual := declarations{}
+	files := map[string]*ast.File{}
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if name == skip || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		files[name] = file
+		for key, signature := range collect(fset, file) {
+			actual[key] = signature
+		}
+	}
+
+	var problems []string
+	for _, class := range spec.Classes {
+		if class.Handwritten == "" {
+			for _, name := range class.Custom {
+				if _, ok := actual[class.Type+"."+name]; !ok {
+					problems = append(problems, fmt.Sprintf("%s.%s: custom method is not declared", class.Type, name))
+				}
+			}
+			continue
+		}
+
+		file, ok := files[class.Handwritten]
+		if !ok || !declaresType(file, class.Type) {
+			problems = append(problems, fmt.Sprintf("%s: type is not declared in %s", class.Type, class.Handwritten))
+			continue
+		}
+
+		keys := map[string]bool{}
+		for key := range expected {
+			if strings.HasPrefix(key, class.Type+".") {
+				keys[key] = true
+			}
+		}
+		for key := range actual {
+			if strings.HasPrefix(key, class.Type+".") && !isCustom(&class, key) {
+				keys[key] = true
+			}
+		}
+		if class.New != "" {
+			keys[class.New] = true
+		}
+		if class.Root != "" {
+			keys["X509EnrollmentRoot."+class.Root] = true
+		}
+
+		for _, key := range sortedKeys(keys) {
+			want, inSpec := expected[key]
+			got, inCode := actual[key]
+			switch {
+			case !inCode:
+				problems = append(problems, fmt.Sprintf("%s%s: described by the spec, missing in %s", key, want, class.Handwritten))
+			case !inSpec:
+				problems = append(problems, fmt.Sprintf("%s%s: missing in the spec", key, got))
+			case want != got:
+				problems = append(problems, fmt.Sprintf("%s: spec %s, code %s", key, want, got))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New("hand-written wrappers differ from the spec:\n\t" + strings.Join(problems, "\n\t"))
+	}
+	fmt.Fprintf(os.Stderr, "cadesgen: %d hand-written wrappers match the spec\n", countHandwritten(spec))
+	return nil
+}
+
+func collect(fset *token.FileSet, file *ast.File) declarations {
+	result := declarations{}
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || !fn.Name.IsExported() {
+			continue
+		}
+
+		key := fn.Name.Name
+		if fn.Recv != nil && len(fn.Recv.List) == 1 {
+			key = receiverType(fn.Recv.List[0].Type) + "." + key
+		}
+		result[key] = signature(fset, fn.Type)
+	}
+	return result
+}
+
+func receiverType(expr ast.Expr) string {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	if ident, ok := expr.(*ast.Ident); ok {
+		return ident.Name
+	}
+	return ""
+}
+
+func signature(fset *token.FileSet, fn *ast.FuncType) string {
+	params := fieldTypes(fset, fn.Params)
+	results := fieldTypes(fset, fn.Results)
+
+	s := "(" + strings.Join(params, ", ") + ")"
+	switch len(results) {
+	case 0:
+	case 1:
+		s += " " + results[0]
+	default:
+		s += " (" + strings.Join(results, ", ") + ")"
+	}
+	return s
+}
+
+func fieldTypes(fset *token.FileSet, fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+
+	var types []string
+	for _, field := range fields.List {
+		var buf bytes.Buffer
+		printer.Fprint(&buf, fset, field.Type)
+
+		count := len(field.Names)
+		if count == 0 {
+			count = 1
+		}
+		for i := 0; i < count; i++ {
+			types = append(types, buf.String())
+		}
+	}
+	return types
+}
+
+func declaresType(file *ast.File, name string) bool {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if spec.(*ast.TypeSpec).Name.Name == name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isCustom(class *Class, key string) bool {
+	for _, name := range class.Custom {
+		if key == class.Type+"."+name {
+			return true
+		}
+	}
+	return false
+}
+
+func countHandwritten(spec *Spec) int {
+	count := 0
+	for _, class := range spec.Classes {
+		if class.Handwritten != "" {
+			count++
+		}
+	}
+	return count
+}
+
+func sortedKeys(keys map[string]bool) []string {
+	result := make([]string, 0, len(keys))
+	for key := range keys {
+		result = append(result, key)
+	}
+	sort.Strings(result)
+	return result
+}
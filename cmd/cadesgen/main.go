@@ -0,0 +1,358 @@
+// Command cadesgen generates typed wrappers of CAdESCOM and X509Enrollment
+// classes from a JSON spec:
+//
+//	go run ./cmd/cadesgen -spec wrappers.json -out wrappers_gen.go
+//
+// Classes marked as handwritten are not written to the output. With -check
+// the generator instead compares their generated API with the hand-written
+// wrappers in the package and verifies that the output file is up to date.
+// Methods listed in custom are written by hand in the package, for generated
+// classes as well.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type Spec struct {
+	Package string  `json:"package"`
+	Classes []Class `json:"classes"`
+}
+
+type Class struct {
+	Type   string `json:"type"`
+	ProgId string `json:"progid,omitempty"`
+	// New is the name of the constructor function taking *Cades, Root the
+	// name of the constructor method of X509EnrollmentRoot.
+	New      string `json:"new,omitempty"`
+	Root     string `json:"root,omitempty"`
+	Receiver string `json:"receiver"`
+	// Handwritten names the file with the wrapper, the generator only checks
+	// it. Custom lists the methods that are written by hand and not described
+	// by the spec.
+	Handwritten string     `json:"handwritten,omitempty"`
+	Custom      []string   `json:"custom,omitempty"`
+	Properties  []Property `json:"properties,omitempty"`
+	Methods     []Method   `json:"methods,omitempty"`
+}
+
+// Property access is "get" (default), "set" or "getset". The setter is
+// named Set<Name>.
+type Property struct {
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+	Access string `json:"access,omitempty"`
+}
+
+// Method takes Params followed by up to Variadic arguments of any type.
+// Func overrides the Go name, empty Result means no return value.
+type Method struct {
+	Name     string  `json:"name"`
+	Func     string  `json:"func,omitempty"`
+	Params   []Param `json:"params,omitempty"`
+	Variadic int     `json:"variadic,omitempty"`
+	Result   string  `json:"result,omitempty"`
+}
+
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+const header = "// Code generated by cadesgen from %s. DO NOT EDIT.\n\n"
+
+func main() {
+	specPath := flag.String("spec", "wrappers.json", "path to the spec")
+	outPath := flag.String("out", "wrappers_gen.go", "path to the generated file")
+	check := flag.Bool("check", false, "compare hand-written wrappers and the generated file with the spec")
+	flag.Parse()
+
+	if err := run(*specPath, *outPath, *check); err != nil {
+		fmt.Fprintln(os.Stderr, "cadesgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath string, outPath string, check bool) error {
+	data, err := os.ReadFile(specPath)
+	if err != nil {
+		return err
+	}
+
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return fmt.Errorf("%s: %w", specPath, err)
+	}
+
+	source, err := generate(&spec, filepath.Base(specPath), false)
+	if err != nil {
+		return err
+	}
+
+	if !check {
+		return os.WriteFile(outPath, source, 0o644)
+	}
+
+	current, err := os.ReadFile(outPath)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(current, source) {
+		return fmt.Errorf("%s is out of date, run go generate", outPath)
+	}
+
+	handwritten, err := generate(&spec, filepath.Base(specPath), true)
+	if err != nil {
+		return err
+	}
+	return checkHandwritten(&spec, handwritten, filepath.Dir(outPath), filepath.Base(outPath))
+}
+
+// generate returns the wrappers of the generated classes, or of the
+// hand-written ones if handwritten is set.
+func generate(spec *Spec, specName string, handwritten bool) ([]byte, error) {
+	g := &generator{}
+	g.printf(header, specName)
+	g.printf("package %s\n", spec.Package)
+	if !handwritten && usesTime(spec) {
+		g.printf("\nimport \"time\"\n")
+	}
+
+	for i := range spec.Classes {
+		class := &spec.Classes[i]
+		if (class.Handwritten != "") != handwritten {
+			continue
+		}
+		if err := g.class(class); err != nil {
+			return nil, fmt.Errorf("%s: %w", class.Type, err)
+		}
+	}
+
+	source, err := format.Source(g.buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %w", err)
+	}
+	return source, nil
+}
+
+func usesTime(spec *Spec) bool {
+	for _, class := range spec.Classes {
+		if class.Handwritten != "" {
+			continue
+		}
+		for _, property := range class.Properties {
+			if property.Type == "time.Time" {
+				return true
+			}
+		}
+		for _, method := range class.Methods {
+			if method.Result == "time.Time" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type generator struct {
+	buf bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...any) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+func (g *generator) class(class *Class) error {
+	t, r := class.Type, class.Receiver
+	if r == "" {
+		return fmt.Errorf("receiver is required")
+	}
+
+	g.printf("\ntype %s CadesObject\n", t)
+	g.printf("\nfunc (%s *%s) Release() error {\n\treturn ReleaseObject((*CadesObject)(%s))\n}\n", r, t, r)
+	g.printf("\nfunc (%s *%s) cadesObject() *CadesObject {\n\treturn (*CadesObject)(%s)\n}\n", r, t, r)
+
+	if class.New != "" || class.Root != "" {
+		if class.ProgId == "" {
+			return fmt.Errorf("progid is required for a constructor")
+		}
+	}
+	if class.New != "" {
+		g.printf("\nfunc %s(cades *Cades) (*%s, error) {\n", class.New, t)
+		g.create("cades", class)
+	}
+	if class.Root != "" {
+		g.printf("\nfunc (x509 *X509EnrollmentRoot) %s() (*%s, error) {\n", class.Root, t)
+		g.create("x509.Cades", class)
+	}
+
+	for _, property := range class.Properties {
+		if err := g.property(class, &property); err != nil {
+			return err
+		}
+	}
+	for _, method := range class.Methods {
+		if err := g.method(class, &method); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *generator) create(cades string, class *Class) {
+	g.printf("\tobj, err := CreateObject(%s, %q)\n", cades, class.ProgId)
+	g.printf("\tif err != nil {\n\t\treturn &%s{}, err\n\t}\n\n", class.Type)
+	g.printf("\treturn (*%s)(obj), nil\n}\n", class.Type)
+}
+
+func (g *generator) property(class *Class, property *Property) error {
+	r, self := class.Receiver, fmt.Sprintf("(*CadesObject)(%s)", class.Receiver)
+
+	access := property.Access
+	if access == "" {
+		access = "get"
+	}
+	if access != "get" && access != "set" && access != "getset" {
+		return fmt.Errorf("%s: unknown access %q", property.Name, access)
+	}
+
+	if access != "set" {
+		g.printf("\nfunc (%s *%s) %s() (%s, error) {\n", r, class.Type, property.Name, property.Type)
+		switch {
+		case isObject(property.Type):
+			g.printf("\tobj, err := GetPropertyWithObject(%s, %q)\n", self, property.Name)
+			g.objectResult(property.Type)
+		case isNumber(property.Type):
+			g.printf("\tvalue, err := GetProperty[float64](%s, %q)\n", self, property.Name)
+			g.printf("\treturn %s(value), err\n}\n", property.Type)
+		case property.Type == "time.Time":
+			g.printf("\tvalue, err := GetProperty[string](%s, %q)\n", self, property.Name)
+			g.printf("\tif err != nil {\n\t\treturn time.Time{}, err\n\t}\n\n")
+			g.printf("\treturn time.Parse(\"2006-01-02T15:04:05.999Z\", value)\n}\n")
+		case property.Type == "string" || property.Type == "bool" || property.Type == "any":
+			g.printf("\treturn GetProperty[%s](%s, %q)\n}\n", property.Type, self, property.Name)
+		default:
+			return fmt.Errorf("%s: unsupported type %q", property.Name, property.Type)
+		}
+	}
+
+	if access != "get" {
+		g.printf("\nfunc (%s *%s) Set%s(value %s) (bool, error) {\n", r, class.Type, property.Name, property.Type)
+		g.printf("\tparam := ValueToParam(%s)\n", argument("value", property.Type))
+		g.printf("\treturn SetProperty(%s, %q, []CadesParam{*param})\n}\n", self, property.Name)
+	}
+	return nil
+}
+
+func (g *generator) method(class *Class, method *Method) error {
+	r, self := class.Receiver, fmt.Sprintf("(*CadesObject)(%s)", class.Receiver)
+
+	name := method.Func
+	if name == "" {
+		name = method.Name
+	}
+
+	var (
+		signature []string
+		arguments []string
+	)
+	for _, param := range method.Params {
+		signature = append(signature, fmt.Sprintf("%s %s", param.Name, param.Type))
+		arguments = append(arguments, argument(param.Name, param.Type))
+	}
+	if method.Variadic > 0 {
+		signature = append(signature, "args ...any")
+	}
+
+	result := "error"
+	if method.Result != "" {
+		result = fmt.Sprintf("(%s, error)", method.Result)
+	}
+	g.printf("\nfunc (%s *%s) %s(%s) %s {\n", r, class.Type, name, strings.Join(signature, ", "), result)
+
+	params := "params"
+	switch {
+	case method.Variadic > 0 && len(arguments) == 0:
+		g.printf("\tparams := ArgumentsToParams(%d, args)\n", method.Variadic)
+	case method.Variadic > 0:
+		g.printf("\tparams := ArgumentsToParams(%d, append([]any{%s}, args...))\n", len(arguments)+method.Variadic, strings.Join(arguments, ", "))
+	case len(arguments) == 0:
+		params = "[]CadesParam{}"
+	case len(arguments) == 1:
+		g.printf("\tparam := ValueToParam(%s)\n", arguments[0])
+		params = "[]CadesParam{*param}"
+	default:
+		g.printf("\tparams := ArgumentsToParams(%d, []any{%s})\n", len(arguments), strings.Join(arguments, ", "))
+	}
+
+	switch {
+	case method.Result == "":
+		g.printf("\treturn CallVoidMethod(%s, %q, %s)\n}\n", self, method.Name, params)
+	case isObject(method.Result):
+		g.printf("\tobj, err := CallMethodWithObject(%s, %q, %s)\n", self, method.Name, params)
+		g.objectResult(method.Result)
+	case method.Result == "string" || method.Result == "bool" || isNumber(method.Result):
+		valueType := method.Result
+		if isNumber(valueType) {
+			valueType = "float64"
+		}
+		zero := zeroValue(method.Result)
+		g.printf("\tdata, err := CallMethod(%s, %q, %s)\n", self, method.Name, params)
+		g.printf("\tif err != nil {\n\t\treturn %s, err\n\t}\n\n", zero)
+		g.printf("\tif value, ok := data.ReturnValue.Value.(%s); ok {\n", valueType)
+		if isNumber(method.Result) {
+			g.printf("\t\treturn %s(value), nil\n\t}\n\n", method.Result)
+		} else {
+			g.printf("\t\treturn value, nil\n\t}\n\n")
+		}
+		g.printf("\treturn %s, ErrEmpty\n}\n", zero)
+	default:
+		return fmt.Errorf("%s: unsupported result %q", method.Name, method.Result)
+	}
+	return nil
+}
+
+func (g *generator) objectResult(resultType string) {
+	t := strings.TrimPrefix(resultType, "*")
+	g.printf("\tif err != nil {\n\t\treturn &%s{}, err\n\t}\n\n", t)
+	g.printf("\treturn (*%s)(obj), nil\n}\n", t)
+}
+
+// argument converts a wrapper object to CadesObject for ValueToParam.
+func argument(name string, paramType string) string {
+	if isObject(paramType) {
+		return fmt.Sprintf("*(*CadesObject)(%s)", name)
+	}
+	return name
+}
+
+func isObject(t string) bool {
+	return strings.HasPrefix(t, "*")
+}
+
+func isNumber(t string) bool {
+	switch t {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return true
+	}
+	return false
+}
+
+func zeroValue(t string) string {
+	switch {
+	case t == "string":
+		return `""`
+	case t == "bool":
+		return "false"
+	case isNumber(t):
+		return "0"
+	}
+	return t + "{}"
+}
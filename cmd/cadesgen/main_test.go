@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"testing"
+)
+
+func loadSpec(t *testing.T) *Spec {
+	t.Helper()
+	data, err := os.ReadFile("../../wrappers.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var spec Spec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatal(err)
+	}
+	return &spec
+}
+
+func TestGenerateUpToDate(t *testing.T) {
+	source, err := generate(loadSpec(t), "wrappers.json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := os.ReadFile("../../wrappers_gen.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(source, current) {
+		t.Error("wrappers_gen.go differs from the generator output, run go generate")
+	}
+}
+
+// TestGenerateHandwritten compares the generated wrappers with the ones that
+// were written by hand before the classes moved to the spec.
+func TestGenerateHandwritten(t *testing.T) {
+	source, err := generate(loadSpec(t), "wrappers.json", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden, err := os.ReadFile("testdata/handwritten.golden")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, types := funcs(t, "handwritten.golden", golden)
+	got, _ := funcs(t, "wrappers_gen.go", source)
+	for key, decl := range want {
+		if got[key] != decl {
+			t.Errorf("%s:\ngenerated:\n%s\nhand-written:\n%s", key, got[key], decl)
+		}
+	}
+	for key := range got {
+		if _, ok := want[key]; !ok && types[receiver(key)] {
+			t.Errorf("%s is generated but was not written by hand", key)
+		}
+	}
+}
+
+// funcs returns the source of the functions of the source by "Type.Method" or
+// "Func" and the declared types.
+func funcs(t *testing.T, name string, source []byte) (map[string]string, map[string]bool) {
+	t.Helper()
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, name, source, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, types := map[string]string{}, map[string]bool{}
+	for _, decl := range file.Decls {
+		switch decl := decl.(type) {
+		case *ast.GenDecl:
+			if decl.Tok == token.TYPE {
+				for _, spec := range decl.Specs {
+					types[spec.(*ast.TypeSpec).Name.Name] = true
+				}
+			}
+		case *ast.FuncDecl:
+			key := decl.Name.Name
+			if decl.Recv != nil {
+				key = receiverType(decl.Recv.List[0].Type) + "." + key
+			}
+			start, end := fset.Position(decl.Pos()).Offset, fset.Position(decl.End()).Offset
+			result[key] = string(source[start:end])
+		}
+	}
+	return result, types
+}
+
+func receiver(key string) string {
+	for i := range key {
+		if key[i] == '.' {
+			return key[:i]
+		}
+	}
+	return ""
+}
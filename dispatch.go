@@ -0,0 +1,163 @@
+package cades
+
+import (
+	"context"
+	"sort"
+)
+
+// DispatchObject is an object of any COM class. Methods and properties are
+// called by name, arguments are converted with ValueToParam:
+//
+//	signer, err := NewDispatchObject(cades, "CAdESCOM.CPSigner")
+//	err = signer.Set("TSAAddress", "http://testca.cryptopro.ru/tsp/tsp.srf")
+//	value, err := signer.Get("TSAAddress")
+type DispatchObject struct {
+	CadesObject
+	methods    []string
+	properties []string
+}
+
+func NewDispatchObject(cades *Cades, progId string) (*DispatchObject, error) {
+	return NewDispatchObjectContext(context.Background(), cades, progId)
+}
+
+func NewDispatchObjectContext(ctx context.Context, cades *Cades, progId string) (*DispatchObject, error) {
+	body := &CadesRequestBody{
+		Data: &CadesRequestData{
+			Destination: "nmcades",
+			Method:      "CreateObject",
+			Params: []CadesParam{
+				{Type: "string", Value: progId},
+			},
+		},
+	}
+
+	data, err := cades.SendRequestContext(ctx, body)
+	if err != nil {
+		return &DispatchObject{}, err
+	}
+
+	return dispatchFromResponse(cades, data)
+}
+
+// AsDispatchObject gives dynamic access to an object of a wrapper, e.g. to a
+// method the wrapper does not have. Methods and Properties of the result are
+// empty, nmcades reports them only when it returns an object.
+func AsDispatchObject(c *CadesObject) *DispatchObject {
+	return &DispatchObject{CadesObject: *c}
+}
+
+func dispatchFromResponse(cades *Cades, data *CadesResponseData) (*DispatchObject, error) {
+	obj, err := objectFromResponse(cades, data)
+	if err != nil {
+		return &DispatchObject{}, err
+	}
+
+	return &DispatchObject{
+		CadesObject: *obj,
+		methods:     sortedNames(data.ReturnValue.Methods),
+		properties:  sortedNames(data.ReturnValue.Properties),
+	}, nil
+}
+
+func sortedNames(names []string) []string {
+	names = append([]string{}, names...)
+	sort.Strings(names)
+	return names
+}
+
+// Methods returns the method names reported by nmcades.
+func (d *DispatchObject) Methods() []string {
+	return append([]string{}, d.methods...)
+}
+
+// Properties returns the property names reported by nmcades.
+func (d *DispatchObject) Properties() []string {
+	return append([]string{}, d.properties...)
+}
+
+func (d *DispatchObject) HasMethod(name string) bool {
+	return containsName(d.methods, name)
+}
+
+func (d *DispatchObject) HasProperty(name string) bool {
+	return containsName(d.properties, name)
+}
+
+func containsName(names []string, name string) bool {
+	i := sort.SearchStrings(names, name)
+	return i < len(names) && names[i] == name
+}
+
+func (d *DispatchObject) Release() error {
+	return ReleaseObject(&d.CadesObject)
+}
+
+// Call calls a method. The result is a string, bool, float64 or, for an
+// object, *DispatchObject.
+func (d *DispatchObject) Call(name string, args ...any) (any, error) {
+	return d.CallContext(context.Background(), name, args...)
+}
+
+func (d *DispatchObject) CallContext(ctx context.Context, name string, args ...any) (any, error) {
+	data, err := CallMethodContext(ctx, &d.CadesObject, name, dispatchParams(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return d.result(data)
+}
+
+// Get reads a property, the result is converted like the result of Call.
+func (d *DispatchObject) Get(name string) (any, error) {
+	return d.GetContext(context.Background(), name)
+}
+
+func (d *DispatchObject) GetContext(ctx context.Context, name string) (any, error) {
+	body := &CadesRequestBody{
+		Data: &CadesRequestData{
+			ObjId:       d.ObjId,
+			Destination: "nmcades",
+			GetProperty: name,
+		},
+	}
+
+	data, err := d.Cades.sendRequest(ctx, body, &d.CadesObject)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.result(data)
+}
+
+func (d *DispatchObject) Set(name string, value any) error {
+	return d.SetContext(context.Background(), name, value)
+}
+
+func (d *DispatchObject) SetContext(ctx context.Context, name string, value any) error {
+	ok, err := SetPropertyContext(ctx, &d.CadesObject, name, dispatchParams([]any{value}))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrProperty
+	}
+
+	return nil
+}
+
+func (d *DispatchObject) result(data *CadesResponseData) (any, error) {
+	if data.ReturnValue.Type == "object" {
+		return dispatchFromResponse(d.Cades, data)
+	}
+
+	return data.ReturnValue.Value, nil
+}
+
+func dispatchParams(args []any) []CadesParam {
+	params := make([]CadesParam, 0, len(args))
+	for _, arg := range args {
+		params = append(params, *ValueToParam(arg))
+	}
+	return params
+}
@@ -0,0 +1,376 @@
+package cades
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// EmulatorMethod implements a method of an emulated class. A returned
+// *EmulatorObject is sent as an object, nil as "OK".
+type EmulatorMethod func(obj *EmulatorObject, params []CadesParam) (any, error)
+
+// EmulatorClass describes a COM class the emulator can create. Properties
+// hold the initial values of a new object; an error value makes reading the
+// property fail with that error.
+type EmulatorClass struct {
+	Name       string
+	Properties map[string]any
+	Methods    map[string]EmulatorMethod
+}
+
+type EmulatorObject struct {
+	Id         uint32
+	Class      *EmulatorClass
+	Properties map[string]any
+	Emulator   *Emulator
+}
+
+// Emulator is an in-memory nmcades that implements Transport. It keeps an
+// object table and answers requests according to the registered classes,
+// so wrappers can be tested without CryptoPro:
+//
+//	emulator := NewEmulator()
+//	emulator.Register(&EmulatorClass{Name: "CAdESCOM.About", Properties: map[string]any{"Version": "2.0.15000"}})
+//	cades, err := NewCadesWithTransport(ctx, emulator)
+type Emulator struct {
+	// InitCallbacks are sent to the client before the init request is
+	// answered, the way nmcades asks for EnableInternalCSP and site approval.
+	InitCallbacks []CallbackData
+
+	mu         sync.Mutex
+	classes    map[string]*EmulatorClass
+	objects    map[string]map[uint32]*EmulatorObject
+	lastObjId  map[string]uint32
+	lastCallId uint32
+	requests   []CadesRequestData
+	tabid      string
+
+	inbox     chan []byte
+	outbox    chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func NewEmulator() *Emulator {
+	emulator := &Emulator{
+		classes:   make(map[string]*EmulatorClass),
+		objects:   make(map[string]map[uint32]*EmulatorObject),
+		lastObjId: make(map[string]uint32),
+		inbox:     make(chan []byte),
+		outbox:    make(chan []byte),
+		closed:    make(chan struct{}),
+	}
+	go emulator.serve()
+	return emulator
+}
+
+func (e *Emulator) Register(class *EmulatorClass) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.classes[class.Name] = class
+}
+
+// NewObject adds an object of the registered class to the object table,
+// methods use it to return new objects.
+func (e *Emulator) NewObject(className string) (*EmulatorObject, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	class, ok := e.classes[className]
+	if !ok {
+		return nil, fmt.Errorf("unknown ProgID: %s", className)
+	}
+
+	e.lastObjId[e.tabid]++
+	obj := &EmulatorObject{
+		Id:         e.lastObjId[e.tabid],
+		Class:      class,
+		Properties: make(map[string]any, len(class.Properties)),
+		Emulator:   e,
+	}
+	for name, value := range class.Properties {
+		obj.Properties[name] = value
+	}
+	e.tabObjects()[obj.Id] = obj
+	return obj, nil
+}
+
+// tabObjects must be called with e.mu held. Like nmcades, the emulator keeps
+// a separate object table for each tab id.
+func (e *Emulator) tabObjects() map[uint32]*EmulatorObject {
+	objects, ok := e.objects[e.tabid]
+	if !ok {
+		objects = make(map[uint32]*EmulatorObject)
+		e.objects[e.tabid] = objects
+	}
+	return objects
+}
+
+// Object returns an object of the tab of the current request.
+func (e *Emulator) Object(id uint32) (*EmulatorObject, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	obj, ok := e.tabObjects()[id]
+	return obj, ok
+}
+
+// Requests returns the requests received so far, callback answers included.
+func (e *Emulator) Requests() []CadesRequestData {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]CadesRequestData{}, e.requests...)
+}
+
+// Callback sends a callback to the client and waits for its answer. It is
+// meant to be called from an EmulatorMethod.
+func (e *Emulator) Callback(callbackType string, value string) ([]CadesParam, error) {
+	e.mu.Lock()
+	e.lastCallId++
+	callback := CallbackData{Id: e.lastCallId, Type: callbackType, Value: value}
+	e.mu.Unlock()
+
+	return e.roundTrip(callback)
+}
+
+func (e *Emulator) Send(message []byte) error {
+	select {
+	case e.inbox <- append([]byte{}, message...):
+		return nil
+	case <-e.closed:
+		return io.ErrClosedPipe
+	}
+}
+
+func (e *Emulator) Receive() ([]byte, error) {
+	select {
+	case message := <-e.outbox:
+		return message, nil
+	case <-e.closed:
+		return nil, io.EOF
+	}
+}
+
+func (e *Emulator) Close() error {
+	e.closeOnce.Do(func() { close(e.closed) })
+	return nil
+}
+
+func (e *Emulator) serve() {
+	for {
+		request, err := e.next()
+		if err != nil {
+			return
+		}
+
+		response := e.handle(request)
+		if err := e.reply(response); err != nil {
+			return
+		}
+	}
+}
+
+// next returns the next request. A malformed one is answered with an error
+// frame and skipped, like nmcades does.
+func (e *Emulator) next() (*CadesRequestData, error) {
+	for {
+		select {
+		case message := <-e.inbox:
+			var body CadesRequestBody
+			if err := json.Unmarshal(message, &body); err != nil || body.Data == nil {
+				malformed := map[string]any{
+					"type":    "error",
+					"message": fmt.Sprintf("emulator: malformed request: %.256s", message),
+				}
+				if err := e.reply(malformed); err != nil {
+					return &CadesRequestData{}, err
+				}
+				continue
+			}
+
+			e.mu.Lock()
+			e.tabid = body.Tabid
+			e.requests = append(e.requests, *body.Data)
+			e.mu.Unlock()
+			return body.Data, nil
+		case <-e.closed:
+			return &CadesRequestData{}, io.EOF
+		}
+	}
+}
+
+func (e *Emulator) reply(data any) error {
+	e.mu.Lock()
+	tabid := e.tabid
+	e.mu.Unlock()
+
+	message, err := json.Marshal(map[string]any{"tabid": tabid, "data": data})
+	if err != nil {
+		return err
+	}
+
+	select {
+	case e.outbox <- message:
+		return nil
+	case <-e.closed:
+		return io.EOF
+	}
+}
+
+func (e *Emulator) roundTrip(callback CallbackData) ([]CadesParam, error) {
+	if err := e.reply(callback); err != nil {
+		return nil, err
+	}
+
+	for {
+		answer, err := e.next()
+		if err != nil {
+			return nil, err
+		}
+		if answer.CallbackId == callback.Id {
+			return answer.Params, nil
+		}
+
+		// a request sent by the callback handler of the client
+		if err := e.reply(e.handle(answer)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (e *Emulator) handle(request *CadesRequestData) map[string]any {
+	response := map[string]any{"requestid": request.RequestId, "type": "result"}
+
+	retval, err := e.dispatch(request)
+	if err != nil {
+		response["type"] = "error"
+		response["message"] = err.Error()
+		return response
+	}
+
+	if retval != nil {
+		response["retval"] = retval
+	}
+	return response
+}
+
+func (e *Emulator) dispatch(request *CadesRequestData) (*ReturnValue, error) {
+	if request.Type == "init" {
+		for _, callback := range e.InitCallbacks {
+			e.mu.Lock()
+			e.lastCallId++
+			callback.Id = e.lastCallId
+			e.mu.Unlock()
+
+			if _, err := e.roundTrip(callback); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	}
+
+	if request.Type == "release" {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if _, ok := e.tabObjects()[request.ObjId]; !ok {
+			return nil, fmt.Errorf("object %d not found", request.ObjId)
+		}
+		delete(e.tabObjects(), request.ObjId)
+		return &ReturnValue{Type: "string", Value: "OK"}, nil
+	}
+
+	if request.Method == "CreateObject" {
+		if len(request.Params) == 0 {
+			return nil, errors.New("CreateObject: ProgID is required")
+		}
+		obj, err := e.NewObject(fmt.Sprint(request.Params[0].Value))
+		if err != nil {
+			return nil, err
+		}
+		return e.returnValue(obj)
+	}
+
+	obj, ok := e.Object(request.ObjId)
+	if !ok {
+		return nil, fmt.Errorf("object %d not found", request.ObjId)
+	}
+
+	switch {
+	case request.GetProperty != "":
+		e.mu.Lock()
+		value, ok := obj.Properties[request.GetProperty]
+		e.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("%s: property %s not found", obj.Class.Name, request.GetProperty)
+		}
+		if err, ok := value.(error); ok {
+			return nil, err
+		}
+		return e.returnValue(value)
+
+	case request.SetProperty != "":
+		if len(request.Params) == 0 {
+			return nil, fmt.Errorf("%s: value for property %s is required", obj.Class.Name, request.SetProperty)
+		}
+		value := e.paramValue(request.Params[0])
+		e.mu.Lock()
+		obj.Properties[request.SetProperty] = value
+		e.mu.Unlock()
+		return e.returnValue(nil)
+
+	case request.Method != "":
+		method, ok := obj.Class.Methods[request.Method]
+		if !ok {
+			return nil, fmt.Errorf("%s: method %s not found", obj.Class.Name, request.Method)
+		}
+		value, err := method(obj, request.Params)
+		if err != nil {
+			return nil, err
+		}
+		return e.returnValue(value)
+	}
+
+	return nil, fmt.Errorf("unsupported request: %+v", *request)
+}
+
+func (e *Emulator) paramValue(param CadesParam) any {
+	if param.Type != "object" {
+		return param.Value
+	}
+
+	if id, ok := param.Value.(float64); ok {
+		if obj, ok := e.Object(uint32(id)); ok {
+			return obj
+		}
+	}
+	return param.Value
+}
+
+func (e *Emulator) returnValue(value any) (*ReturnValue, error) {
+	switch v := value.(type) {
+	case nil:
+		return &ReturnValue{Type: "string", Value: "OK"}, nil
+	case *EmulatorObject:
+		retval := &ReturnValue{Type: "object", Value: v.Id}
+		for name := range v.Class.Methods {
+			retval.Methods = append(retval.Methods, name)
+		}
+		for name := range v.Class.Properties {
+			retval.Properties = append(retval.Properties, name)
+		}
+		sort.Strings(retval.Methods)
+		sort.Strings(retval.Properties)
+		return retval, nil
+	case string:
+		return &ReturnValue{Type: "string", Value: v}, nil
+	case bool:
+		return &ReturnValue{Type: "boolean", Value: v}, nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return &ReturnValue{Type: "number", Value: v}, nil
+	}
+
+	return nil, fmt.Errorf("emulator: unsupported return value %T", value)
+}
@@ -0,0 +1,321 @@
+package cades
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+var storeClasses = []*EmulatorClass{
+	{
+		Name:       "CAdESCOM.Store",
+		Properties: map[string]any{"Certificates": errors.New("store is not open")},
+		Methods: map[string]EmulatorMethod{
+			"Open": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				certificates, err := obj.Emulator.NewObject("CAdESCOM.Certificates")
+				if err != nil {
+					return nil, err
+				}
+
+				var items []*EmulatorObject
+				for _, thumbprint := range []string{"aa", "bb"} {
+					certificate, err := obj.Emulator.NewObject("CAdESCOM.Certificate")
+					if err != nil {
+						return nil, err
+					}
+					certificate.Properties["Thumbprint"] = thumbprint
+					items = append(items, certificate)
+				}
+				certificates.Properties["Count"] = len(items)
+				certificates.Properties["items"] = items
+				obj.Properties["Certificates"] = certificates
+				return nil, nil
+			},
+			"Close": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				return nil, nil
+			},
+		},
+	},
+	{
+		Name:       "CAdESCOM.Certificates",
+		Properties: map[string]any{"Count": 0},
+		Methods: map[string]EmulatorMethod{
+			"Item": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				items := obj.Properties["items"].([]*EmulatorObject)
+				index, ok := params[0].Value.(float64)
+				if !ok || index < 1 || int(index) > len(items) {
+					return nil, fmt.Errorf("invalid index %v", params[0].Value)
+				}
+				return items[int(index)-1], nil
+			},
+		},
+	},
+	{
+		Name:       "CAdESCOM.Certificate",
+		Properties: map[string]any{"Thumbprint": ""},
+	},
+}
+
+func TestEmulatorStore(t *testing.T) {
+	cades, emulator := newEmulatorCades(t, storeClasses...)
+
+	store, err := NewStore(cades)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Certificates(); err == nil || !strings.Contains(err.Error(), "store is not open") {
+		t.Errorf("Certificates() of a closed store: error = %v", err)
+	}
+	if err := store.Open(CAPICOM_CURRENT_USER_STORE, CAPICOM_MY_STORE, CAPICOM_STORE_OPEN_MAXIMUM_ALLOWED); err != nil {
+		t.Fatal(err)
+	}
+
+	certificates, err := store.Certificates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := certificates.Count()
+	if err != nil || count != 2 {
+		t.Fatalf("Count() = %d, %v, want 2", count, err)
+	}
+
+	certificate, err := certificates.Item(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thumbprint, err := certificate.Thumbprint()
+	if err != nil || thumbprint != "bb" {
+		t.Errorf("Item(2).Thumbprint() = %q, %v, want bb", thumbprint, err)
+	}
+	if _, err := certificates.Item(3); !errors.As(err, new(*NmcadesError)) {
+		t.Errorf("Item(3) error = %v, want *NmcadesError", err)
+	}
+
+	var open *CadesRequestData
+	requests := emulator.Requests()
+	for i := range requests {
+		if requests[i].Method == "Open" {
+			open = &requests[i]
+		}
+	}
+	if open == nil || len(open.Params) != 3 || open.Params[1].Value != CAPICOM_MY_STORE {
+		t.Errorf("Open request = %+v", open)
+	}
+	if err := store.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestEmulatorX509EnrollmentRoot(t *testing.T) {
+	cades, emulator := newEmulatorCades(t,
+		&EmulatorClass{
+			Name:       "X509Enrollment.CObjectId",
+			Properties: map[string]any{"Value": "", "FriendlyName": ""},
+			Methods: map[string]EmulatorMethod{
+				"InitializeFromValue": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					obj.Properties["Value"] = params[0].Value
+					obj.Properties["FriendlyName"] = GostAlgorithmNames[fmt.Sprint(params[0].Value)]
+					return nil, nil
+				},
+			},
+		},
+		&EmulatorClass{Name: "X509Enrollment.CX509PrivateKey"},
+		&EmulatorClass{
+			Name:       "X509Enrollment.CCspInformations",
+			Properties: map[string]any{"Count": 0},
+			Methods: map[string]EmulatorMethod{
+				"AddAvailableCsps": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					obj.Properties["Count"] = 1
+					return nil, nil
+				},
+				"ItemByIndex": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					info, err := obj.Emulator.NewObject("X509Enrollment.CCspInformation")
+					if err != nil {
+						return nil, err
+					}
+					info.Properties["Name"] = "Crypto-Pro GOST R 34.10-2012 Cryptographic Service Provider"
+					return info, nil
+				},
+			},
+		},
+		&EmulatorClass{Name: "X509Enrollment.CCspInformation", Properties: map[string]any{"Name": ""}},
+	)
+	root := CreateX509EnrollmentRoot(cades)
+
+	oid, err := root.CObjectId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := oid.InitializeFromValue("1.2.643.7.1.1.1.1"); err != nil {
+		t.Fatal(err)
+	}
+	if name, err := oid.FriendlyName(); err != nil || name != "ГОСТ Р 34.11-2012 256 бит" {
+		t.Errorf("FriendlyName() = %q, %v", name, err)
+	}
+
+	privateKey, err := root.CX509PrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := privateKey.SetExisting(true); err != nil || !ok {
+		t.Fatalf("SetExisting() = %t, %v", ok, err)
+	}
+	if obj, _ := emulator.Object(privateKey.ObjId); obj == nil || obj.Properties["Existing"] != true {
+		t.Errorf("emulated private key %d has no Existing property", privateKey.ObjId)
+	}
+
+	infos, err := root.CCspInformations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := infos.AddAvailableCsps(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := infos.ItemByIndex(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name, err := info.Name(); err != nil || !strings.HasPrefix(name, "Crypto-Pro") {
+		t.Errorf("Name() = %q, %v", name, err)
+	}
+
+	if _, err := root.CX509Enrollment(); !errors.As(err, new(*NmcadesError)) {
+		t.Errorf("CX509Enrollment() of an unregistered class: error = %v, want *NmcadesError", err)
+	}
+	if live := cades.LiveObjects(); live != 4 {
+		t.Errorf("LiveObjects() = %d, want 4", live)
+	}
+}
+
+func TestEmulatorCallback(t *testing.T) {
+	cades, _ := newEmulatorCades(t, testObjectClass, &EmulatorClass{
+		Name: "Test.Prompt",
+		Methods: map[string]EmulatorMethod{
+			"Ask": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				answer, err := obj.Emulator.Callback("callback", CallbackPrompt+"('PIN')")
+				if err != nil {
+					return nil, err
+				}
+				return fmt.Sprint(answer[0].Value), nil
+			},
+		},
+	})
+
+	pin, err := NewDispatchObject(cades, "Test.Object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := pin.Set("Value", "1234"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the handler reads the answer with a nested request
+	cades.HandleCallback(CallbackPrompt, func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		value, err := GetPropertyContext[string](ctx, &pin.CadesObject, "Value")
+		if err != nil {
+			return nil, err
+		}
+		return &CallbackResult{Params: []CadesParam{{Type: "string", Value: value}}}, nil
+	})
+
+	prompt, err := NewDispatchObject(cades, "Test.Prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	answer, err := prompt.Call("Ask")
+	if err != nil || answer != "1234" {
+		t.Errorf("Call(Ask) = %v, %v, want 1234", answer, err)
+	}
+}
+
+// TestCallbackScope counts and releases objects from a callback handler,
+// which runs while the request being answered holds the session lock.
+func TestCallbackScope(t *testing.T) {
+	cades, _ := newEmulatorCades(t, testObjectClass, &EmulatorClass{
+		Name: "Test.Prompt",
+		Methods: map[string]EmulatorMethod{
+			"Ask": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				_, err := obj.Emulator.Callback("callback", CallbackPrompt+"('PIN')")
+				return nil, err
+			},
+		},
+	})
+
+	var live []int
+	cades.HandleCallback(CallbackPrompt, func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		scope := cades.NewScope()
+		if _, err := CreateObjectContext(ctx, scope.Cades, "Test.Object"); err != nil {
+			return nil, err
+		}
+		live = append(live, cades.LiveObjects())
+		if err := scope.ReleaseContext(ctx); err != nil {
+			return nil, err
+		}
+		live = append(live, cades.LiveObjects())
+		return &CallbackResult{Params: []CadesParam{{Type: "string", Value: "1234"}}}, nil
+	})
+
+	prompt, err := NewDispatchObject(cades, "Test.Prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := prompt.Call("Ask")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback handler deadlocked on the session lock")
+	}
+	if len(live) != 2 || live[0] != 2 || live[1] != 1 {
+		t.Errorf("LiveObjects() in the handler = %v, want [2 1]", live)
+	}
+}
+
+func TestEmulatorMalformedRequest(t *testing.T) {
+	emulator := NewEmulator()
+	defer emulator.Close()
+	emulator.Register(testObjectClass)
+
+	done := make(chan error, 1)
+	go func() {
+		if err := emulator.Send([]byte("not json")); err != nil {
+			done <- err
+			return
+		}
+		message, err := emulator.Receive()
+		if err != nil {
+			done <- err
+			return
+		}
+		if !strings.Contains(string(message), `"type":"error"`) {
+			done <- fmt.Errorf("answer to a malformed request: %s", message)
+			return
+		}
+
+		cades, err := NewCadesWithTransport(context.Background(), emulator)
+		if err != nil {
+			done <- err
+			return
+		}
+		done <- roundTripObject(cades, "after malformed")
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("emulator stopped answering after a malformed request")
+	}
+}
@@ -0,0 +1,218 @@
+package cades
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"unicode/utf8"
+)
+
+// maxLoggedMessage caps the part of a message written to the debug log.
+const maxLoggedMessage = 4096
+
+var errLogLimit = errors.New("log limit reached")
+
+// escapedB and escapedF follow encoding/json, which escapes \b and \f as such
+// since Go 1.22 and as \u0008 and \u000c before.
+var escapedB, escapedF = jsonEscape('\b'), jsonEscape('\f')
+
+// safeJSON marks the ASCII bytes that encoding/json writes as is.
+var safeJSON = func() (safe [utf8.RuneSelf]bool) {
+	for b := 0x20; b < utf8.RuneSelf; b++ {
+		safe[b] = b != '"' && b != '\\' && b != '<' && b != '>' && b != '&'
+	}
+	return safe
+}()
+
+func jsonEscape(b byte) string {
+	encoded, _ := json.Marshal(string(b))
+	return string(encoded[1 : len(encoded)-1])
+}
+
+// writeRequest writes request as json.Marshal would. String params are
+// escaped straight into w, so large base64 content is never copied into an
+// intermediate buffer.
+func writeRequest(w io.Writer, request *CadesRequestBody) error {
+	data := *request.Data
+	params := data.Params
+	data.Params = nil
+
+	head, err := json.Marshal(&CadesRequestBody{Tabid: request.Tabid, Data: &data})
+	if err != nil {
+		return err
+	}
+	if len(params) == 0 {
+		_, err := w.Write(head)
+		return err
+	}
+
+	// head ends with the braces of data and of the body
+	head = head[:len(head)-2]
+	if _, err := w.Write(head); err != nil {
+		return err
+	}
+	prefix := `,"params":[`
+	if head[len(head)-1] == '{' {
+		prefix = prefix[1:]
+	}
+	if _, err := io.WriteString(w, prefix); err != nil {
+		return err
+	}
+
+	for i, param := range params {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := writeParam(w, &param); err != nil {
+			return err
+		}
+	}
+
+	_, err = io.WriteString(w, "]}}")
+	return err
+}
+
+func writeParam(w io.Writer, param *CadesParam) error {
+	value, ok := param.Value.(string)
+	if !ok {
+		encoded, err := json.Marshal(param)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(encoded)
+		return err
+	}
+
+	paramType, err := json.Marshal(param.Type)
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `{"type":`); err != nil {
+		return err
+	}
+	if _, err := w.Write(paramType); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, `,"value":"`); err != nil {
+		return err
+	}
+	if err := writeJSONString(w, value); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, `"}`)
+	return err
+}
+
+// writeJSONString writes s escaped the way encoding/json does, without the
+// quotes. Runs that need no escaping are written as is.
+func writeJSONString(w io.Writer, s string) error {
+	const hex = "0123456789abcdef"
+
+	escape := func(start, i int, escaped string) error {
+		if start < i {
+			if _, err := io.WriteString(w, s[start:i]); err != nil {
+				return err
+			}
+		}
+		_, err := io.WriteString(w, escaped)
+		return err
+	}
+
+	start := 0
+	for i := 0; i < len(s); {
+		if b := s[i]; b < utf8.RuneSelf {
+			if safeJSON[b] {
+				i++
+				continue
+			}
+
+			var escaped string
+			switch b {
+			case '"', '\\':
+				escaped = `\` + string(b)
+			case '\n':
+				escaped = `\n`
+			case '\r':
+				escaped = `\r`
+			case '\t':
+				escaped = `\t`
+			case '\b':
+				escaped = escapedB
+			case '\f':
+				escaped = escapedF
+			default:
+				escaped = `\u00` + string(hex[b>>4]) + string(hex[b&0xF])
+			}
+			if err := escape(start, i, escaped); err != nil {
+				return err
+			}
+			i++
+			start = i
+			continue
+		}
+
+		c, size := utf8.DecodeRuneInString(s[i:])
+		switch {
+		case c == utf8.RuneError && size == 1:
+			if err := escape(start, i, "\ufffd"); err != nil {
+				return err
+			}
+			start = i + size
+		case c == '\u2028' || c == '\u2029':
+			if err := escape(start, i, `\u202`+string(hex[c&0xF])); err != nil {
+				return err
+			}
+			start = i + size
+		}
+		i += size
+	}
+
+	if start < len(s) {
+		_, err := io.WriteString(w, s[start:])
+		return err
+	}
+	return nil
+}
+
+// countingWriter counts the bytes of a message without keeping them.
+type countingWriter struct {
+	n int
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += len(p)
+	return len(p), nil
+}
+
+func (w *countingWriter) WriteString(s string) (int, error) {
+	w.n += len(s)
+	return len(s), nil
+}
+
+// limitWriter keeps the first maxLoggedMessage bytes of a message for the
+// debug log and stops the encoding after that with errLogLimit.
+type limitWriter struct {
+	buf []byte
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	free := maxLoggedMessage - len(w.buf)
+	if len(p) > free {
+		w.buf = append(w.buf, p[:free]...)
+		return free, errLogLimit
+	}
+	w.buf = append(w.buf, p...)
+	return len(p), nil
+}
+
+func (w *limitWriter) WriteString(s string) (int, error) {
+	free := maxLoggedMessage - len(w.buf)
+	if len(s) > free {
+		w.buf = append(w.buf, s[:free]...)
+		return free, errLogLimit
+	}
+	w.buf = append(w.buf, s...)
+	return len(s), nil
+}
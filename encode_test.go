@@ -0,0 +1,192 @@
+package cades
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"testing"
+)
+
+func TestWriteRequestMatchesMarshal(t *testing.T) {
+	tests := []struct {
+		name    string
+		request *CadesRequestBody
+	}{
+		{"no params", &CadesRequestBody{Tabid: "tab", Data: &CadesRequestData{RequestId: 1, Type: "property", GetProperty: "Value"}}},
+		{"empty params", &CadesRequestBody{Data: &CadesRequestData{Method: "Close", Params: []CadesParam{}}}},
+		{"params only", &CadesRequestBody{Data: &CadesRequestData{Params: []CadesParam{{Type: "string", Value: "a"}}}}},
+		{"escapes", stringRequest("quote \" backslash \\ <tag> & \n\r\t\b\f \x00\x1f\x7f")},
+		{"invalid UTF-8", stringRequest("\xff a\xc3 \xed\xa0\x80 \xf0\x9f")},
+		{"line separators", stringRequest("a\u2028b\u2029c")},
+		{"unicode", stringRequest("ГОСТ Р 34.10-2012 😀 \ufffd")},
+		{"empty string", stringRequest("")},
+		{"tabid escapes", &CadesRequestBody{Tabid: "<tab >", Data: &CadesRequestData{Method: "Call", Params: []CadesParam{{Type: "string", Value: "&"}}}}},
+		{"non-string params", &CadesRequestBody{Tabid: "tab", Data: &CadesRequestData{
+			RequestId: 3,
+			ObjId:     2,
+			Method:    "Call",
+			Params: []CadesParam{
+				{Type: "number", Value: 1.5},
+				{Type: "number", Value: -7},
+				{Type: "boolean", Value: false},
+				{Type: "object", Value: uint32(4), generation: 1},
+				{Type: "null", Value: nil},
+				{Type: "string", Value: "<b>"},
+				{Type: "a\"b", Value: []any{"x", 1}},
+			},
+		}}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			want, err := json.Marshal(test.request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := writeRequest(&buf, test.request); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), want) {
+				t.Errorf("writeRequest() =\n%s\nwant\n%s", buf.Bytes(), want)
+			}
+		})
+	}
+}
+
+func FuzzWriteJSONString(f *testing.F) {
+	for _, seed := range []string{"", "abc", "\"\\<>&", "\x00\x1f\n", "\b\f", "\xff\xc3", "\u2028\u2029", "ГОСТ 😀"} {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		want, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := writeJSONString(&buf, s); err != nil {
+			t.Fatal(err)
+		}
+		if got := `"` + buf.String() + `"`; got != string(want) {
+			t.Errorf("writeJSONString(%q) = %s, want %s", s, got, want)
+		}
+	})
+}
+
+func stringRequest(value string) *CadesRequestBody {
+	return &CadesRequestBody{
+		Tabid: "tab",
+		Data: &CadesRequestData{
+			RequestId: 2,
+			ObjId:     1,
+			Type:      "method",
+			Method:    "Import",
+			Params:    []CadesParam{{Type: "string", Value: value}, {Type: "number", Value: 0}},
+		},
+	}
+}
+
+// benchmarkRequest returns a request with about 100 MB of base64 content,
+// the size of a large document to sign.
+func benchmarkRequest(b *testing.B) *CadesRequestBody {
+	b.Helper()
+	data := make([]byte, 75<<20)
+	if _, err := rand.Read(data); err != nil {
+		b.Fatal(err)
+	}
+	request := stringRequest(base64.StdEncoding.EncodeToString(data))
+	request.Data.Method = "SetContent"
+	return request
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// discardTransport is a FuncSender that frames messages into io.Discard.
+type discardTransport struct {
+	framer *Framer
+}
+
+func (t *discardTransport) Send(message []byte) error {
+	return t.framer.WriteFrame(message)
+}
+
+func (t *discardTransport) SendFunc(length int, write func(w io.Writer) error) error {
+	return t.framer.WriteFrameFunc(length, write)
+}
+
+func (t *discardTransport) Receive() ([]byte, error) {
+	return nil, io.EOF
+}
+
+func (t *discardTransport) Close() error {
+	return nil
+}
+
+func BenchmarkEncode(b *testing.B) {
+	request := benchmarkRequest(b)
+	size := len(request.Data.Params[0].Value.(string))
+
+	b.Run("Marshal+PostMessage", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(size))
+		for i := 0; i < b.N; i++ {
+			message, err := json.Marshal(request)
+			if err != nil {
+				b.Fatal(err)
+			}
+			if err := PostMessage(nopWriteCloser{io.Discard}, message); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("writeRequest", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(size))
+		for i := 0; i < b.N; i++ {
+			if err := writeRequest(io.Discard, request); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("WriteFrameFunc", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(size))
+		framer := NewFramer(nil, io.Discard)
+		framer.MaxMessageSize = 0
+		for i := 0; i < b.N; i++ {
+			var counter countingWriter
+			if err := writeRequest(&counter, request); err != nil {
+				b.Fatal(err)
+			}
+			err := framer.WriteFrameFunc(counter.n, func(w io.Writer) error {
+				return writeRequest(w, request)
+			})
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("send", func(b *testing.B) {
+		b.ReportAllocs()
+		b.SetBytes(int64(size))
+		framer := NewFramer(nil, io.Discard)
+		framer.MaxMessageSize = 0
+		cades := &Cades{Transport: &discardTransport{framer: framer}}
+		for i := 0; i < b.N; i++ {
+			if err := cades.send(request, request.Data.RequestId); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
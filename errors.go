@@ -10,4 +10,17 @@ var (
 	ErrContainerExists        = errors.New("container exists")
 	ErrContainerNotExportable = errors.New("container not exportable")
 	ErrCertificateNotExists   = errors.New("certificate not exists")
+	ErrShortFrame             = errors.New("short frame")
+	ErrFrameTooLarge          = errors.New("frame exceeds maximum message size")
+	ErrPoolClosed             = errors.New("pool closed")
+	ErrProcessExited          = errors.New("nmcades process exited")
+	ErrCadesClosed            = errors.New("cades session closed")
+	ErrStaleObject            = errors.New("object belongs to a previous nmcades process")
+	ErrTabInUse               = errors.New("tab id is already in use")
+	ErrReplayMismatch         = errors.New("request does not match the recording")
+	ErrWrongPin               = errors.New("wrong pin")
+	ErrKeyNotFound            = errors.New("key not found")
+	ErrCancelledByUser        = errors.New("cancelled by user")
+	ErrLicenseExpired         = errors.New("license expired")
+	ErrUnknownAlgorithm       = errors.New("unknown algorithm")
 )
@@ -0,0 +1,3 @@
+package cades
+
+//go:generate go run ./cmd/cadesgen -spec wrappers.json -out wrappers_gen.go
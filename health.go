@@ -0,0 +1,85 @@
+package cades
+
+import (
+	"context"
+	"time"
+)
+
+// SessionInfo describes a session and its current nmcades process.
+type SessionInfo struct {
+	PluginVersion PluginVersion
+	CSPVersion    CadesVersion
+	// PID is zero if the session runs over a transport other than a local
+	// process.
+	PID int
+	// StartedAt is the time of the init handshake with the current process.
+	StartedAt time.Time
+	// Requests is the number of requests answered by the current process.
+	Requests    uint64
+	LiveObjects int
+	Generation  uint32
+}
+
+// Ping checks that nmcades answers requests: it creates CAdESCOM.About,
+// reads its version and releases it. If ctx is done first the session is
+// closed, see SendRequestContext.
+func (cades *Cades) Ping(ctx context.Context) error {
+	about, err := CreateObjectContext(ctx, cades, "CAdESCOM.About")
+	if err != nil {
+		return err
+	}
+
+	_, err = GetPropertyContext[string](ctx, about, "Version")
+	if releaseErr := ReleaseObjectContext(ctx, about); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+// Info returns the versions reported by nmcades and the counters of the
+// session. The objects used to read the versions are released.
+func (cades *Cades) Info() (*SessionInfo, error) {
+	return cades.InfoContext(context.Background())
+}
+
+// InfoContext is Info with a context, see SendRequestContext.
+func (cades *Cades) InfoContext(ctx context.Context) (*SessionInfo, error) {
+	info := &SessionInfo{}
+	err := cades.withScopeContext(ctx, func(scoped *Cades) error {
+		var err error
+		if info.PluginVersion, err = GetPluginVersion(scoped); err != nil {
+			return err
+		}
+		info.CSPVersion, err = GetCadesVersion(scoped)
+		return err
+	})
+	if err != nil {
+		return &SessionInfo{}, err
+	}
+
+	if err := cades.readInfo(ctx, info); err != nil {
+		return &SessionInfo{}, err
+	}
+	return info, nil
+}
+
+func (cades *Cades) readInfo(ctx context.Context, info *SessionInfo) error {
+	if cades.parent != nil {
+		return cades.parent.readInfo(ctx, info)
+	}
+
+	unlock, err := cades.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if cades.Process != nil && cades.Process.Cmd != nil && cades.Process.Cmd.Process != nil {
+		info.PID = cades.Process.Cmd.Process.Pid
+	}
+	info.StartedAt = cades.startedAt
+	info.Requests = cades.requests
+	info.LiveObjects = len(cades.objects)
+	info.Generation = cades.Generation
+	return nil
+}
@@ -0,0 +1,199 @@
+package cades
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// newAboutClasses returns CAdESCOM.About with the plugin version 2.0.15000
+// and the CSP version 5.0.12000.
+func newAboutClasses() []*EmulatorClass {
+	return []*EmulatorClass{
+		{
+			Name:       "CAdESCOM.About",
+			Properties: map[string]any{"Version": "2.0.15000", "PluginVersion": errors.New("no plugin version")},
+			Methods: map[string]EmulatorMethod{
+				"CSPVersion": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					return newEmulatedVersion(obj.Emulator, 5, 0, 12000)
+				},
+			},
+		},
+		{
+			Name:       "CAdESCOM.Version",
+			Properties: map[string]any{"MajorVersion": 0, "MinorVersion": 0, "BuildVersion": 0},
+		},
+	}
+}
+
+func newEmulatedVersion(emulator *Emulator, major, minor, build int) (*EmulatorObject, error) {
+	version, err := emulator.NewObject("CAdESCOM.Version")
+	if err != nil {
+		return nil, err
+	}
+	version.Properties["MajorVersion"] = major
+	version.Properties["MinorVersion"] = minor
+	version.Properties["BuildVersion"] = build
+	return version, nil
+}
+
+// newAboutCades starts a session over an emulator with the About classes.
+// The plugin version objects are created ahead of the requests that return
+// them, the warnings about their ids are discarded.
+func newAboutCades(t *testing.T, classes []*EmulatorClass) (*Cades, *Emulator) {
+	t.Helper()
+
+	emulator := NewEmulator()
+	for _, class := range classes {
+		emulator.Register(class)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	cades, err := NewCadesWithTransport(context.Background(), emulator, WithLogger(logger))
+	if err != nil {
+		t.Fatalf("NewCadesWithTransport: %s", err)
+	}
+	t.Cleanup(func() { cades.Close() })
+	return cades, emulator
+}
+
+// setPluginVersion gives the next About objects a new plugin version object,
+// the previous one is released with the scope that read it.
+func setPluginVersion(t *testing.T, emulator *Emulator, about *EmulatorClass) {
+	t.Helper()
+
+	version, err := newEmulatedVersion(emulator, 2, 0, 15000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	about.Properties["PluginVersion"] = version
+}
+
+func TestPing(t *testing.T) {
+	classes := newAboutClasses()
+	cades, emulator := newEmulatorCades(t, classes...)
+
+	if err := cades.Ping(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() = %d, want 0", live)
+	}
+
+	var ops []string
+	for _, request := range emulator.Requests() {
+		if request.Type != "init" {
+			ops = append(ops, requestOp(&request))
+		}
+	}
+	want := []string{"CreateObject CAdESCOM.About", "get_property Version", "release"}
+	if len(ops) != len(want) {
+		t.Fatalf("requests = %q, want %q", ops, want)
+	}
+	for i := range want {
+		if ops[i] != want[i] {
+			t.Errorf("requests = %q, want %q", ops, want)
+			break
+		}
+	}
+
+	classes[0].Properties["Version"] = errors.New("nmcades is broken")
+	if err := cades.Ping(context.Background()); !errors.As(err, new(*NmcadesError)) {
+		t.Errorf("Ping() error = %v, want *NmcadesError", err)
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() after a failed Ping = %d, want 0", live)
+	}
+}
+
+func TestInfo(t *testing.T) {
+	classes := newAboutClasses()
+	cades, emulator := newAboutCades(t, classes)
+
+	setPluginVersion(t, emulator, classes[0])
+	info, err := cades.Info()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.PluginVersion != (PluginVersion{2, 0, 15000}) {
+		t.Errorf("PluginVersion = %+v, want 2.0.15000", info.PluginVersion)
+	}
+	if info.CSPVersion != (CadesVersion{5, 0, 12000}) {
+		t.Errorf("CSPVersion = %+v, want 5.0.12000", info.CSPVersion)
+	}
+	if info.PID != 0 || info.StartedAt.IsZero() || info.Requests == 0 || info.Generation != 0 {
+		t.Errorf("Info() = %+v", info)
+	}
+	if info.LiveObjects != 0 {
+		t.Errorf("LiveObjects = %d, want 0", info.LiveObjects)
+	}
+
+	setPluginVersion(t, emulator, classes[0])
+	next, err := cades.InfoContext(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if next.Requests <= info.Requests || !next.StartedAt.Equal(info.StartedAt) {
+		t.Errorf("InfoContext() = %+v after %+v", next, info)
+	}
+
+	classes[0].Properties["PluginVersion"] = errors.New("no plugin version")
+	if _, err := cades.InfoContext(context.Background()); !errors.As(err, new(*NmcadesError)) {
+		t.Errorf("InfoContext() error = %v, want *NmcadesError", err)
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() after a failed InfoContext = %d, want 0", live)
+	}
+}
+
+// TestInfoCallback reads the info from a callback handler, which runs while
+// the request being answered holds the session lock.
+func TestInfoCallback(t *testing.T) {
+	classes := append(newAboutClasses(), &EmulatorClass{
+		Name: "Test.Prompt",
+		Methods: map[string]EmulatorMethod{
+			"Ask": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				_, err := obj.Emulator.Callback("callback", CallbackPrompt+"('PIN')")
+				return nil, err
+			},
+		},
+	})
+	cades, emulator := newAboutCades(t, classes)
+
+	var info *SessionInfo
+	cades.HandleCallback(CallbackPrompt, func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		var err error
+		if info, err = cades.InfoContext(ctx); err != nil {
+			return nil, err
+		}
+		return &CallbackResult{Params: []CadesParam{{Type: "string", Value: "1234"}}}, nil
+	})
+
+	prompt, err := NewDispatchObject(cades, "Test.Prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	setPluginVersion(t, emulator, classes[0])
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := prompt.Call("Ask")
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("InfoContext in a callback handler deadlocked on the session lock")
+	}
+	if info == nil || info.PluginVersion != (PluginVersion{2, 0, 15000}) || info.LiveObjects != 1 {
+		t.Errorf("InfoContext() in the handler = %+v", info)
+	}
+}
@@ -0,0 +1,256 @@
+package cades
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"golang.org/x/exp/slog"
+)
+
+// Mux runs several sessions over one nmcades process. Each session has its
+// own tab id, request counter and objects, responses are routed to the
+// session by tabid:
+//
+//	process, err := NewNMCadesProcess()
+//	mux := NewMux(process)
+//	tenant, err := mux.NewCades(ctx, "tenant-1")
+//
+// A done context of a request closes only its session, later requests of the
+// session return *InterruptedError (ErrCadesClosed). The shared process is
+// not killed, so if nmcades hangs the requests of the other sessions wait for
+// their contexts; Close stops the process and fails them all.
+type Mux struct {
+	transport Transport
+	log       *slog.Logger
+
+	writeMu  sync.Mutex
+	mu       sync.Mutex
+	sessions map[string]*muxTransport
+	done     chan struct{}
+	err      error
+}
+
+// MuxConfig customizes a Mux. Logger receives the diagnostics of the mux and
+// is the default logger of its sessions, slog.Default() if it is nil.
+type MuxConfig struct {
+	Logger *slog.Logger
+}
+
+func NewMux(transport Transport) *Mux {
+	return NewMuxWithConfig(transport, MuxConfig{})
+}
+
+func NewMuxWithConfig(transport Transport, config MuxConfig) *Mux {
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+
+	mux := &Mux{
+		transport: transport,
+		log:       config.Logger,
+		sessions:  make(map[string]*muxTransport),
+		done:      make(chan struct{}),
+	}
+	go mux.read()
+	return mux
+}
+
+// NewCades starts a session with the given tab id. Closing the session
+// leaves the process and the other sessions running. The session logs to the
+// logger of the mux unless opts set another one. Objects of the session
+// stay in nmcades until they are released, see WithScope.
+func (mux *Mux) NewCades(ctx context.Context, tabId string, opts ...Option) (*Cades, error) {
+	transport, err := mux.open(tabId)
+	if err != nil {
+		return &Cades{}, err
+	}
+
+	opts = append([]Option{WithLogger(mux.log)}, opts...)
+	opts = append(opts, WithTabId(tabId))
+	cades, err := NewCadesWithTransport(ctx, transport, opts...)
+	if err != nil {
+		transport.Close()
+	}
+	return cades, err
+}
+
+// Sessions returns the number of open sessions.
+func (mux *Mux) Sessions() int {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	return len(mux.sessions)
+}
+
+// Close closes the transport, pending and later requests of all sessions
+// fail.
+func (mux *Mux) Close() error {
+	return mux.transport.Close()
+}
+
+func (mux *Mux) open(tabId string) (*muxTransport, error) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+
+	select {
+	case <-mux.done:
+		return nil, mux.err
+	default:
+	}
+
+	if _, ok := mux.sessions[tabId]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrTabInUse, tabId)
+	}
+
+	transport := &muxTransport{
+		mux:    mux,
+		tabId:  tabId,
+		ready:  make(chan struct{}, 1),
+		closed: make(chan struct{}),
+	}
+	mux.sessions[tabId] = transport
+	return transport, nil
+}
+
+func (mux *Mux) read() {
+	for {
+		message, err := mux.transport.Receive()
+		if err != nil {
+			mux.mu.Lock()
+			mux.err = err
+			mux.mu.Unlock()
+			close(mux.done)
+			return
+		}
+
+		var body struct {
+			Tabid string `json:"tabid"`
+		}
+		if err := json.Unmarshal(message, &body); err != nil {
+			mux.log.Debug(fmt.Sprintf("[Mux.read] Fail to parse json: %s", err))
+			continue
+		}
+
+		mux.mu.Lock()
+		session, ok := mux.sessions[body.Tabid]
+		mux.mu.Unlock()
+		if !ok {
+			mux.log.Debug(fmt.Sprintf("[Mux.read] Drop message for closed tab %q", body.Tabid))
+			continue
+		}
+		session.push(message)
+	}
+}
+
+func (mux *Mux) send(message []byte) error {
+	mux.writeMu.Lock()
+	defer mux.writeMu.Unlock()
+	return mux.transport.Send(message)
+}
+
+func (mux *Mux) sendFunc(length int, write func(w io.Writer) error) error {
+	mux.writeMu.Lock()
+	defer mux.writeMu.Unlock()
+
+	sender, ok := mux.transport.(FuncSender)
+	if !ok {
+		var buf bytes.Buffer
+		if err := write(&buf); err != nil {
+			return err
+		}
+		return mux.transport.Send(buf.Bytes())
+	}
+	return sender.SendFunc(length, write)
+}
+
+func (mux *Mux) remove(session *muxTransport) {
+	mux.mu.Lock()
+	defer mux.mu.Unlock()
+	if mux.sessions[session.tabId] == session {
+		delete(mux.sessions, session.tabId)
+	}
+}
+
+// muxTransport is the transport of a session of Mux. Messages are queued so
+// a slow session does not block the others.
+type muxTransport struct {
+	mux   *Mux
+	tabId string
+
+	mu        sync.Mutex
+	queue     [][]byte
+	ready     chan struct{}
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+func (t *muxTransport) push(message []byte) {
+	t.mu.Lock()
+	t.queue = append(t.queue, message)
+	t.mu.Unlock()
+
+	select {
+	case t.ready <- struct{}{}:
+	default:
+	}
+}
+
+func (t *muxTransport) pop() ([]byte, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.queue) == 0 {
+		return nil, false
+	}
+	message := t.queue[0]
+	t.queue = t.queue[1:]
+	return message, true
+}
+
+func (t *muxTransport) Send(message []byte) error {
+	select {
+	case <-t.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+	return t.mux.send(message)
+}
+
+func (t *muxTransport) SendFunc(length int, write func(w io.Writer) error) error {
+	select {
+	case <-t.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+	return t.mux.sendFunc(length, write)
+}
+
+func (t *muxTransport) Receive() ([]byte, error) {
+	for {
+		if message, ok := t.pop(); ok {
+			return message, nil
+		}
+
+		select {
+		case <-t.ready:
+		case <-t.closed:
+			return nil, io.ErrClosedPipe
+		case <-t.mux.done:
+			if message, ok := t.pop(); ok {
+				return message, nil
+			}
+			return nil, t.mux.err
+		}
+	}
+}
+
+func (t *muxTransport) Close() error {
+	t.closeOnce.Do(func() {
+		close(t.closed)
+		t.mux.remove(t)
+	})
+	return nil
+}
@@ -0,0 +1,112 @@
+package cades
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMuxCancelledSession(t *testing.T) {
+	release := make(chan struct{})
+	emulator := NewEmulator()
+	emulator.Register(&EmulatorClass{
+		Name:       "Test.Object",
+		Properties: map[string]any{"Value": ""},
+		Methods: map[string]EmulatorMethod{
+			"Wait": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				<-release
+				return nil, nil
+			},
+		},
+	})
+	mux := NewMux(emulator)
+	defer mux.Close()
+
+	hung, err := mux.NewCades(context.Background(), "hung")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := mux.NewCades(context.Background(), "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	obj, err := NewDispatchObject(hung, "Test.Object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := obj.CallContext(ctx, "Wait"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("CallContext() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	_, err = obj.Get("Value")
+	if !errors.Is(err, ErrCadesClosed) || errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Get() of a cancelled session: error = %v, want %v", err, ErrCadesClosed)
+	}
+	if err := hung.Close(); err != nil {
+		t.Errorf("Close() of a cancelled session: %s", err)
+	}
+	if sessions := mux.Sessions(); sessions != 1 {
+		t.Errorf("Sessions() = %d, want 1", sessions)
+	}
+
+	// the shared process still runs the hung request, the other session
+	// waits for it
+	close(release)
+	if err := roundTripObject(other, "other"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMuxClosedSession(t *testing.T) {
+	emulator := NewEmulator()
+	emulator.Register(testObjectClass)
+	mux := NewMux(emulator)
+	defer mux.Close()
+
+	cades, err := mux.NewCades(context.Background(), "closed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cades.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewDispatchObject(cades, "Test.Object"); !errors.Is(err, ErrCadesClosed) {
+		t.Errorf("NewDispatchObject() of a closed session: error = %v, want %v", err, ErrCadesClosed)
+	}
+}
+
+func TestMuxLogger(t *testing.T) {
+	logger, logs := testLogger()
+	emulator := NewEmulator()
+	emulator.Register(testObjectClass)
+	mux := NewMuxWithConfig(emulator, MuxConfig{Logger: logger})
+	defer mux.Close()
+
+	cades, err := mux.NewCades(context.Background(), "logged")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := roundTripObject(cades, "logged"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "[Cades.send] Send message") {
+		t.Errorf("session does not log to the logger of the mux: %q", logs.String())
+	}
+
+	// the answer to a request of a closed session is dropped
+	if err := cades.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := mux.send([]byte(`{"tabid":"logged","data":{"type":"release","objid":1}}`)); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the dropped message to be logged", func() bool {
+		return strings.Contains(logs.String(), "[Mux.read] Drop message")
+	})
+}
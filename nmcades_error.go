@@ -0,0 +1,111 @@
+package cades
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+type hresultInfo struct {
+	name     string
+	sentinel error
+}
+
+var hresults = map[uint32]hresultInfo{
+	0x80004005: {"E_FAIL", nil},
+	0x80070057: {"E_INVALIDARG", nil},
+	0x800704C7: {"ERROR_CANCELLED", ErrCancelledByUser},
+	0x80090008: {"NTE_BAD_ALGID", nil},
+	0x8009000B: {"NTE_BAD_KEY_STATE", nil},
+	0x8009000D: {"NTE_NO_KEY", ErrKeyNotFound},
+	0x8009000F: {"NTE_EXISTS", ErrContainerExists},
+	0x80090010: {"NTE_PERM", nil},
+	0x80090016: {"NTE_BAD_KEYSET", ErrKeyNotFound},
+	0x80090020: {"NTE_FAIL", nil},
+	0x80092004: {"CRYPT_E_NOT_FOUND", nil},
+	0x8010002C: {"SCARD_E_NO_KEY_CONTAINER", ErrKeyNotFound},
+	0x8010006B: {"SCARD_W_WRONG_CHV", ErrWrongPin},
+	0x8010006E: {"SCARD_W_CANCELLED_BY_USER", ErrCancelledByUser},
+	0x800B0101: {"CERT_E_EXPIRED", nil},
+	0x800B0109: {"CERT_E_UNTRUSTEDROOT", nil},
+	0x800B010A: {"CERT_E_CHAINING", nil},
+}
+
+var hresultPattern = regexp.MustCompile(`0[xX][0-9a-fA-F]{8}`)
+
+// NmcadesError is returned when nmcades answers a request with an error. It
+// matches ErrWrongPin, ErrKeyNotFound, ErrCancelledByUser, ErrLicenseExpired
+// and ErrContainerExists with errors.Is when the code is known.
+type NmcadesError struct {
+	Message string
+	// HResult is the last code found in the message, zero if there is none.
+	HResult uint32
+	// Name is the symbolic name of HResult, empty for unknown codes.
+	Name  string
+	ObjId uint32
+	// Op is the failed operation: "CreateObject CAdESCOM.Store", "method
+	// Sign", "get_property Version", "set_property Content", "init" or
+	// "release".
+	Op string
+}
+
+func newNmcadesError(request *CadesRequestData, message string) *NmcadesError {
+	err := &NmcadesError{
+		Message: message,
+		ObjId:   request.ObjId,
+		Op:      requestOp(request),
+	}
+
+	if codes := hresultPattern.FindAllString(message, -1); len(codes) > 0 {
+		code, parseErr := strconv.ParseUint(codes[len(codes)-1][2:], 16, 32)
+		if parseErr == nil {
+			err.HResult = uint32(code)
+			err.Name = hresults[err.HResult].name
+		}
+	}
+	return err
+}
+
+func requestOp(request *CadesRequestData) string {
+	switch {
+	case request.Method == "CreateObject":
+		if len(request.Params) > 0 {
+			return fmt.Sprintf("%s %v", request.Method, request.Params[0].Value)
+		}
+		return request.Method
+	case request.Method != "":
+		return "method " + request.Method
+	case request.GetProperty != "":
+		return "get_property " + request.GetProperty
+	case request.SetProperty != "":
+		return "set_property " + request.SetProperty
+	}
+	return request.Type
+}
+
+func (e *NmcadesError) Error() string {
+	message := fmt.Sprintf("[nmcades] %s: %s", e.Op, e.Message)
+	if e.Name != "" {
+		message = fmt.Sprintf("%s (%s)", message, e.Name)
+	}
+	return message
+}
+
+func (e *NmcadesError) Is(target error) bool {
+	if target == nil {
+		return false
+	}
+	if info, ok := hresults[e.HResult]; ok && info.sentinel == target {
+		return true
+	}
+	return target == ErrLicenseExpired && isLicenseMessage(e.Message)
+}
+
+// isLicenseMessage recognizes expired CSP, TSP and OCSP licenses, nmcades
+// reports them with different codes.
+func isLicenseMessage(message string) bool {
+	message = strings.ToLower(message)
+	return (strings.Contains(message, "лицензи") && (strings.Contains(message, "истек") || strings.Contains(message, "истёк"))) ||
+		(strings.Contains(message, "license") && strings.Contains(message, "expired"))
+}
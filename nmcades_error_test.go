@@ -0,0 +1,128 @@
+package cades
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNmcadesError(t *testing.T) {
+	sentinels := []error{ErrWrongPin, ErrKeyNotFound, ErrCancelledByUser, ErrLicenseExpired, ErrContainerExists}
+	tests := []struct {
+		name    string
+		request CadesRequestData
+		message string
+		op      string
+		hresult uint32
+		errName string
+		is      error
+		text    string
+	}{
+		{
+			name:    "CreateObject",
+			request: CadesRequestData{Method: "CreateObject", Params: []CadesParam{{Type: "string", Value: "CAdESCOM.Store"}}},
+			message: "Набор ключей не существует. (0x80090016)",
+			op:      "CreateObject CAdESCOM.Store",
+			hresult: 0x80090016,
+			errName: "NTE_BAD_KEYSET",
+			is:      ErrKeyNotFound,
+			text:    "[nmcades] CreateObject CAdESCOM.Store: Набор ключей не существует. (0x80090016) (NTE_BAD_KEYSET)",
+		},
+		{
+			name:    "wrong pin",
+			request: CadesRequestData{ObjId: 3, Method: "SignCades"},
+			message: "Указан неправильный PIN. (0x8010006B)",
+			op:      "method SignCades",
+			hresult: 0x8010006B,
+			errName: "SCARD_W_WRONG_CHV",
+			is:      ErrWrongPin,
+		},
+		{
+			name:    "last code wins",
+			request: CadesRequestData{Method: "SignCades"},
+			message: "Ошибка 0x80004005: Операция отменена пользователем. (0x800704C7)",
+			op:      "method SignCades",
+			hresult: 0x800704C7,
+			errName: "ERROR_CANCELLED",
+			is:      ErrCancelledByUser,
+		},
+		{
+			name:    "lower case code",
+			request: CadesRequestData{Method: "GenerateKey"},
+			message: "Keyset exists (0x8009000f)",
+			op:      "method GenerateKey",
+			hresult: 0x8009000F,
+			errName: "NTE_EXISTS",
+			is:      ErrContainerExists,
+		},
+		{
+			name:    "unknown code",
+			request: CadesRequestData{GetProperty: "Version"},
+			message: "Something failed (0x12345678)",
+			op:      "get_property Version",
+			hresult: 0x12345678,
+			text:    "[nmcades] get_property Version: Something failed (0x12345678)",
+		},
+		{
+			name:    "no code",
+			request: CadesRequestData{SetProperty: "Content"},
+			message: "Invalid argument",
+			op:      "set_property Content",
+		},
+		{
+			name:    "CSP license",
+			request: CadesRequestData{Method: "SignCades"},
+			message: "Истёк срок действия лицензии на КриптоПро CSP. (0x80090020)",
+			op:      "method SignCades",
+			hresult: 0x80090020,
+			errName: "NTE_FAIL",
+			is:      ErrLicenseExpired,
+		},
+		{
+			name:    "TSP license",
+			request: CadesRequestData{Method: "EnhanceCades"},
+			message: "The TSP license has expired",
+			op:      "method EnhanceCades",
+			is:      ErrLicenseExpired,
+		},
+		{
+			name:    "license without yo",
+			request: CadesRequestData{Type: "init"},
+			message: "Лицензия OCSP истекла",
+			op:      "init",
+			is:      ErrLicenseExpired,
+		},
+		{
+			name:    "release",
+			request: CadesRequestData{ObjId: 5, Type: "release"},
+			message: "object 5 not found",
+			op:      "release",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := newNmcadesError(&test.request, test.message)
+			if err.Op != test.op || err.HResult != test.hresult || err.Name != test.errName || err.ObjId != test.request.ObjId {
+				t.Errorf("newNmcadesError() = %+v, want op %q, code %#x, name %q", err, test.op, test.hresult, test.errName)
+			}
+			for _, sentinel := range sentinels {
+				if got := errors.Is(err, sentinel); got != (sentinel == test.is) {
+					t.Errorf("errors.Is(%v) = %t", sentinel, got)
+				}
+			}
+			if test.text != "" && err.Error() != test.text {
+				t.Errorf("Error() = %q, want %q", err.Error(), test.text)
+			}
+		})
+	}
+}
+
+func TestNmcadesErrorCreateObject(t *testing.T) {
+	cades, _ := newEmulatorCades(t)
+
+	_, err := CreateObject(cades, "CAdESCOM.Missing")
+	var nmcadesErr *NmcadesError
+	if !errors.As(err, &nmcadesErr) || nmcadesErr.Op != "CreateObject CAdESCOM.Missing" {
+		t.Errorf("CreateObject() error = %v, want an error of CreateObject CAdESCOM.Missing", err)
+	}
+}
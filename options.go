@@ -0,0 +1,120 @@
+package cades
+
+import (
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+type cadesOptions struct {
+	process           ProcessConfig
+	tabId             string
+	originURL         string
+	enableInternalCSP bool
+	logger            *slog.Logger
+	handshakeTimeout  time.Duration
+	autoRestart       bool
+	recorder          *Recorder
+}
+
+type Option func(options *cadesOptions)
+
+func newCadesOptions(opts []Option) *cadesOptions {
+	options := &cadesOptions{
+		tabId:     "CadesAgent",
+		originURL: defaultOriginURL,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// WithBinaryPath runs the given nmcades binary instead of searching the
+// CryptoPro folders, e.g. to choose between CSP 4 and CSP 5.
+func WithBinaryPath(path string) Option {
+	return func(options *cadesOptions) {
+		options.process.Path = path
+	}
+}
+
+// WithEnv adds "KEY=value" variables to the environment of nmcades.
+func WithEnv(env ...string) Option {
+	return func(options *cadesOptions) {
+		options.process.Env = append(options.process.Env, env...)
+	}
+}
+
+func WithWorkingDir(dir string) Option {
+	return func(options *cadesOptions) {
+		options.process.Dir = dir
+	}
+}
+
+// WithGracePeriod sets how long Close waits for nmcades to exit before it
+// is killed, see ProcessConfig.
+func WithGracePeriod(gracePeriod time.Duration) Option {
+	return func(options *cadesOptions) {
+		options.process.GracePeriod = gracePeriod
+	}
+}
+
+// WithMaxMessageSize limits the size of a message in both directions. It
+// applies to the nmcades process, restarted ones included, and to a
+// *ConnTransport passed to NewCadesWithTransport.
+func WithMaxMessageSize(size uint32) Option {
+	return func(options *cadesOptions) {
+		options.process.MaxMessageSize = size
+	}
+}
+
+func WithTabId(tabId string) Option {
+	return func(options *cadesOptions) {
+		options.tabId = tabId
+	}
+}
+
+// WithOriginURL sets the url the session reports to the plugin, it is
+// checked against the site approval rules.
+func WithOriginURL(url string) Option {
+	return func(options *cadesOptions) {
+		options.originURL = url
+	}
+}
+
+// WithInternalCSP sets the answer to the cadesplugin.EnableInternalCSP
+// callback.
+func WithInternalCSP(enable bool) Option {
+	return func(options *cadesOptions) {
+		options.enableInternalCSP = enable
+	}
+}
+
+func WithLogger(logger *slog.Logger) Option {
+	return func(options *cadesOptions) {
+		options.logger = logger
+		options.process.Logger = logger
+	}
+}
+
+// WithHandshakeTimeout bounds the init handshake of a new or restarted
+// nmcades process.
+func WithHandshakeTimeout(timeout time.Duration) Option {
+	return func(options *cadesOptions) {
+		options.handshakeTimeout = timeout
+	}
+}
+
+func WithAutoRestart(enable bool) Option {
+	return func(options *cadesOptions) {
+		options.autoRestart = enable
+	}
+}
+
+// WithRecorder writes the traffic of the session to recorder, see
+// NewReplayTransport to play it back.
+func WithRecorder(recorder *Recorder) Option {
+	return func(options *cadesOptions) {
+		options.recorder = recorder
+	}
+}
@@ -0,0 +1,325 @@
+package cades
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+type CadesPoolConfig struct {
+	// MinSessions are started with the pool and kept alive by idle eviction.
+	// A session that is recycled or discarded is replaced in the background
+	// while the pool holds fewer.
+	MinSessions int
+	// MaxSessions limits borrowed and idle sessions together, 1 if not set.
+	MaxSessions int
+	// IdleTimeout closes sessions idle for longer, zero keeps them forever.
+	IdleTimeout time.Duration
+	// MaxUses recycles a session after it was borrowed that many times,
+	// zero means no limit.
+	MaxUses int
+	// New starts a session, NewCadesContext if not set.
+	New func(ctx context.Context) (*Cades, error)
+	// HealthCheck is run on an idle session before it is borrowed,
+	// CheckCadesHealth if not set.
+	HealthCheck func(ctx context.Context, cades *Cades) error
+	// Logger receives the diagnostics of the pool and, if New is not set,
+	// of its sessions. slog.Default() if not set.
+	Logger *slog.Logger
+}
+
+type pooledCades struct {
+	cades     *Cades
+	uses      int
+	idleSince time.Time
+}
+
+// CadesPool keeps nmcades sessions for reuse across requests.
+type CadesPool struct {
+	config CadesPoolConfig
+
+	mu       sync.Mutex
+	idle     []*pooledCades
+	borrowed map[*Cades]*pooledCades
+	closed   bool
+	// refilling is set while refill starts sessions up to MinSessions.
+	refilling bool
+
+	slots chan struct{}
+	stop  chan struct{}
+}
+
+func NewCadesPool(ctx context.Context, config CadesPoolConfig) (*CadesPool, error) {
+	if config.MaxSessions <= 0 {
+		config.MaxSessions = 1
+	}
+	if config.MinSessions > config.MaxSessions {
+		config.MinSessions = config.MaxSessions
+	}
+	if config.Logger == nil {
+		config.Logger = slog.Default()
+	}
+	if config.New == nil {
+		logger := config.Logger
+		config.New = func(ctx context.Context) (*Cades, error) {
+			return NewCadesContext(ctx, WithLogger(logger))
+		}
+	}
+	if config.HealthCheck == nil {
+		config.HealthCheck = CheckCadesHealth
+	}
+
+	pool := &CadesPool{
+		config:   config,
+		borrowed: make(map[*Cades]*pooledCades),
+		slots:    make(chan struct{}, config.MaxSessions),
+		stop:     make(chan struct{}),
+	}
+
+	for i := 0; i < config.MinSessions; i++ {
+		cades, err := config.New(ctx)
+		if err != nil {
+			if cades != nil {
+				cades.Close()
+			}
+			pool.Close()
+			return pool, err
+		}
+		pool.idle = append(pool.idle, &pooledCades{cades: cades, idleSince: time.Now()})
+	}
+
+	if config.IdleTimeout > 0 {
+		go pool.evictIdle()
+	}
+
+	return pool, nil
+}
+
+// CheckCadesHealth creates a CAdESCOM.About object, the cheapest call that
+// reaches the plugin.
+func CheckCadesHealth(ctx context.Context, cades *Cades) error {
+	about, err := CreateObjectContext(ctx, cades, "CAdESCOM.About")
+	if err != nil {
+		return err
+	}
+	return ReleaseObjectContext(ctx, about)
+}
+
+// Get borrows a session, waiting until one is available or ctx is done.
+// The session must be returned with Put or Discard.
+func (pool *CadesPool) Get(ctx context.Context) (*Cades, error) {
+	select {
+	case pool.slots <- struct{}{}:
+	case <-ctx.Done():
+		return &Cades{}, ctx.Err()
+	}
+
+	for {
+		pooled, ok, err := pool.popIdle()
+		if err != nil {
+			<-pool.slots
+			return &Cades{}, err
+		}
+		if !ok {
+			break
+		}
+
+		if err := pool.config.HealthCheck(ctx, pooled.cades); err != nil {
+			pool.config.Logger.Debug(fmt.Sprintf("[CadesPool.Get] Health check failed: %s", err))
+			pooled.cades.Close()
+			go pool.refill()
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				<-pool.slots
+				return &Cades{}, ctxErr
+			}
+			continue
+		}
+
+		return pool.lend(pooled), nil
+	}
+
+	cades, err := pool.config.New(ctx)
+	if err != nil {
+		if cades != nil {
+			cades.Close()
+		}
+		<-pool.slots
+		return &Cades{}, err
+	}
+
+	return pool.lend(&pooledCades{cades: cades}), nil
+}
+
+// Put returns a borrowed session to the pool.
+func (pool *CadesPool) Put(cades *Cades) {
+	pool.mu.Lock()
+	pooled, ok := pool.borrowed[cades]
+	if !ok {
+		pool.mu.Unlock()
+		return
+	}
+	delete(pool.borrowed, cades)
+
+	pooled.uses++
+	recycle := pool.closed || (pool.config.MaxUses > 0 && pooled.uses >= pool.config.MaxUses)
+	if !recycle {
+		pooled.idleSince = time.Now()
+		pool.idle = append(pool.idle, pooled)
+	}
+	pool.mu.Unlock()
+
+	if recycle {
+		cades.Close()
+		go pool.refill()
+	}
+	<-pool.slots
+}
+
+// Discard closes a borrowed session that should not be reused, e.g. after
+// a failed or cancelled request.
+func (pool *CadesPool) Discard(cades *Cades) {
+	pool.mu.Lock()
+	_, ok := pool.borrowed[cades]
+	delete(pool.borrowed, cades)
+	pool.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	cades.Close()
+	go pool.refill()
+	<-pool.slots
+}
+
+// Close closes the idle sessions, borrowed ones are closed when returned.
+func (pool *CadesPool) Close() {
+	pool.mu.Lock()
+	if pool.closed {
+		pool.mu.Unlock()
+		return
+	}
+	pool.closed = true
+	idle := pool.idle
+	pool.idle = nil
+	pool.mu.Unlock()
+
+	close(pool.stop)
+	for _, pooled := range idle {
+		pooled.cades.Close()
+	}
+}
+
+func (pool *CadesPool) popIdle() (*pooledCades, bool, error) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	if pool.closed {
+		return nil, false, ErrPoolClosed
+	}
+
+	last := len(pool.idle) - 1
+	if last < 0 {
+		return nil, false, nil
+	}
+
+	pooled := pool.idle[last]
+	pool.idle = pool.idle[:last]
+	return pooled, true, nil
+}
+
+func (pool *CadesPool) lend(pooled *pooledCades) *Cades {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	pool.borrowed[pooled.cades] = pooled
+	return pooled.cades
+}
+
+// minEvictPeriod keeps the eviction ticker valid for a tiny IdleTimeout.
+const minEvictPeriod = time.Millisecond
+
+func (pool *CadesPool) evictIdle() {
+	period := pool.config.IdleTimeout / 2
+	if period < minEvictPeriod {
+		period = minEvictPeriod
+	}
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-pool.stop:
+			return
+		}
+
+		var expired []*pooledCades
+		pool.mu.Lock()
+		total := len(pool.idle) + len(pool.borrowed)
+		kept := pool.idle[:0]
+		for _, pooled := range pool.idle {
+			if total > pool.config.MinSessions && time.Since(pooled.idleSince) > pool.config.IdleTimeout {
+				expired = append(expired, pooled)
+				total--
+				continue
+			}
+			kept = append(kept, pooled)
+		}
+		pool.idle = kept
+		pool.mu.Unlock()
+
+		for _, pooled := range expired {
+			pool.config.Logger.Debug("[CadesPool] Close idle session")
+			pooled.cades.Close()
+		}
+		pool.refill()
+	}
+}
+
+// refill starts idle sessions until the pool holds MinSessions. Only one
+// refill runs at a time, a failed start is retried by the next one.
+func (pool *CadesPool) refill() {
+	pool.mu.Lock()
+	if pool.refilling {
+		pool.mu.Unlock()
+		return
+	}
+	pool.refilling = true
+	pool.mu.Unlock()
+
+	defer func() {
+		pool.mu.Lock()
+		pool.refilling = false
+		pool.mu.Unlock()
+	}()
+
+	for {
+		pool.mu.Lock()
+		missing := !pool.closed && len(pool.idle)+len(pool.borrowed) < pool.config.MinSessions
+		pool.mu.Unlock()
+		if !missing {
+			return
+		}
+
+		cades, err := pool.config.New(context.Background())
+		if err != nil {
+			pool.config.Logger.Debug(fmt.Sprintf("[CadesPool.refill] Fail to start session: %s", err))
+			if cades != nil {
+				cades.Close()
+			}
+			return
+		}
+
+		pool.mu.Lock()
+		if pool.closed {
+			pool.mu.Unlock()
+			cades.Close()
+			return
+		}
+		pool.idle = append(pool.idle, &pooledCades{cades: cades, idleSince: time.Now()})
+		pool.mu.Unlock()
+	}
+}
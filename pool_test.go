@@ -0,0 +1,286 @@
+package cades
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestPool returns a pool whose sessions run on emulators of their own.
+// started counts the sessions started by the pool.
+func newTestPool(t *testing.T, config CadesPoolConfig) (*CadesPool, *atomic.Int32) {
+	t.Helper()
+
+	started := &atomic.Int32{}
+	if config.New == nil {
+		config.New = func(ctx context.Context) (*Cades, error) {
+			emulator := NewEmulator()
+			emulator.Register(testObjectClass)
+			emulator.Register(&EmulatorClass{Name: "CAdESCOM.About"})
+			started.Add(1)
+			return NewCadesWithTransport(ctx, emulator)
+		}
+	}
+
+	pool, err := NewCadesPool(context.Background(), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(pool.Close)
+	return pool, started
+}
+
+func (pool *CadesPool) sessions() (idle int, borrowed int) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+	return len(pool.idle), len(pool.borrowed)
+}
+
+// eventually waits up to a second for condition.
+func eventually(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestPoolReuse(t *testing.T) {
+	pool, started := newTestPool(t, CadesPoolConfig{MinSessions: 1})
+
+	cades, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := roundTripObject(cades, "pooled"); err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(cades)
+
+	again, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Put(again)
+	if again != cades || started.Load() != 1 {
+		t.Errorf("Get() started a new session, want the returned one")
+	}
+}
+
+func TestPoolGetContext(t *testing.T) {
+	pool, _ := newTestPool(t, CadesPoolConfig{MaxSessions: 1})
+
+	cades, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Put(cades)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := pool.Get(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Get() of an exhausted pool: error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	var sick atomic.Pointer[Cades]
+	pool, started := newTestPool(t, CadesPoolConfig{
+		HealthCheck: func(ctx context.Context, cades *Cades) error {
+			if cades == sick.Load() {
+				return errors.New("sick")
+			}
+			return CheckCadesHealth(ctx, cades)
+		},
+	})
+
+	cades, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(cades)
+	sick.Store(cades)
+
+	healthy, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Put(healthy)
+	if healthy == cades || started.Load() != 2 {
+		t.Error("Get() returned a session that failed the health check")
+	}
+	if !cades.isClosed() {
+		t.Error("session that failed the health check is not closed")
+	}
+}
+
+func TestPoolMaxUses(t *testing.T) {
+	pool, _ := newTestPool(t, CadesPoolConfig{MaxUses: 2})
+
+	first, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(first)
+	second, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(second)
+
+	if second != first || !first.isClosed() {
+		t.Fatal("session is not recycled after MaxUses")
+	}
+	third, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pool.Put(third)
+	if third == first {
+		t.Error("Get() returned a recycled session")
+	}
+}
+
+func TestPoolIdleEviction(t *testing.T) {
+	pool, _ := newTestPool(t, CadesPoolConfig{MinSessions: 1, MaxSessions: 3, IdleTimeout: 20 * time.Millisecond})
+
+	var borrowed []*Cades
+	for i := 0; i < 3; i++ {
+		cades, err := pool.Get(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		borrowed = append(borrowed, cades)
+	}
+	for _, cades := range borrowed {
+		pool.Put(cades)
+	}
+
+	eventually(t, "idle sessions to be evicted", func() bool {
+		idle, _ := pool.sessions()
+		return idle == 1
+	})
+	closed := 0
+	for _, cades := range borrowed {
+		if cades.isClosed() {
+			closed++
+		}
+	}
+	if closed != 2 {
+		t.Errorf("%d evicted sessions are closed, want 2", closed)
+	}
+}
+
+func TestPoolMinSessions(t *testing.T) {
+	pool, started := newTestPool(t, CadesPoolConfig{MinSessions: 2, MaxSessions: 2, MaxUses: 1})
+
+	cades, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Discard(cades)
+	eventually(t, "the discarded session to be replaced", func() bool {
+		idle, _ := pool.sessions()
+		return idle == 2
+	})
+
+	cades, err = pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(cades)
+	eventually(t, "the recycled session to be replaced", func() bool {
+		idle, _ := pool.sessions()
+		return idle == 2 && started.Load() == 4
+	})
+}
+
+func TestPoolShortIdleTimeout(t *testing.T) {
+	pool, _ := newTestPool(t, CadesPoolConfig{IdleTimeout: time.Nanosecond})
+	cades, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(cades)
+	eventually(t, "the idle session to be evicted", func() bool {
+		idle, _ := pool.sessions()
+		return idle == 0
+	})
+}
+
+func TestPoolClosed(t *testing.T) {
+	pool, _ := newTestPool(t, CadesPoolConfig{MinSessions: 1, MaxSessions: 2})
+
+	put, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	discarded, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Close()
+	pool.Put(put)
+	pool.Discard(discarded)
+	if !put.isClosed() || !discarded.isClosed() {
+		t.Error("sessions returned to a closed pool are not closed")
+	}
+	if idle, borrowed := pool.sessions(); idle != 0 || borrowed != 0 {
+		t.Errorf("closed pool holds %d idle and %d borrowed sessions", idle, borrowed)
+	}
+	if _, err := pool.Get(context.Background()); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("Get() of a closed pool: error = %v, want %v", err, ErrPoolClosed)
+	}
+}
+
+func TestPoolNewFails(t *testing.T) {
+	fail := errors.New("no nmcades")
+	config := CadesPoolConfig{
+		New: func(ctx context.Context) (*Cades, error) {
+			return nil, fail
+		},
+	}
+
+	config.MinSessions = 1
+	if _, err := NewCadesPool(context.Background(), config); !errors.Is(err, fail) {
+		t.Errorf("NewCadesPool() error = %v, want %v", err, fail)
+	}
+
+	config.MinSessions = 0
+	pool, _ := newTestPool(t, config)
+	if _, err := pool.Get(context.Background()); !errors.Is(err, fail) {
+		t.Errorf("Get() error = %v, want %v", err, fail)
+	}
+}
+
+func TestPoolLogger(t *testing.T) {
+	logger, logs := testLogger()
+	pool, _ := newTestPool(t, CadesPoolConfig{
+		Logger: logger,
+		HealthCheck: func(ctx context.Context, cades *Cades) error {
+			return errors.New("sick")
+		},
+	})
+
+	cades, err := pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(cades)
+	cades, err = pool.Get(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Put(cades)
+
+	if !strings.Contains(logs.String(), "Health check failed: sick") {
+		t.Errorf("failed health check is not logged to the configured logger: %q", logs.String())
+	}
+}
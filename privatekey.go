@@ -2,6 +2,14 @@ package cades
 
 type PrivateKey CadesObject
 
+func (pk *PrivateKey) Release() error {
+	return ReleaseObject((*CadesObject)(pk))
+}
+
+func (pk *PrivateKey) cadesObject() *CadesObject {
+	return (*CadesObject)(pk)
+}
+
 func (pk *PrivateKey) ProviderName() (string, error) {
 	return GetProperty[string]((*CadesObject)(pk), "ProviderName")
 }
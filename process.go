@@ -2,15 +2,18 @@ package cades
 
 import (
 	"bufio"
-	"bytes"
 	"encoding/binary"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
+	"sync"
+	"time"
 	"unsafe"
 
 	"golang.org/x/exp/slog"
@@ -19,10 +22,64 @@ import (
 
 var nativeEndian binary.ByteOrder
 
+// DefaultMaxMessageSize limits the size of a single nmcades message.
+const DefaultMaxMessageSize uint32 = 1 << 30
+
+// exitWaitTimeout bounds how long a failed read or write waits for the
+// process to be reaped before the error is reported as is.
+const exitWaitTimeout = time.Second
+
+// stderrWaitDelay bounds how long Wait copies stderr after nmcades exited. A
+// child of nmcades that inherited stderr would otherwise keep Wait, and the
+// reaping of the process, blocked until it exits.
+const stderrWaitDelay = time.Second
+
+// DefaultGracePeriod is how long Close waits for nmcades to exit after its
+// stdin is closed before the process is killed.
+const DefaultGracePeriod = 2 * time.Second
+
 type CadesProcess struct {
 	Cmd    *exec.Cmd
 	Stdout *io.ReadCloser
 	Stdin  *io.WriteCloser
+	Framer *Framer
+	// GracePeriod is how long Close waits for a clean exit, a zero or
+	// negative value kills the process at once.
+	GracePeriod time.Duration
+
+	exited    chan struct{}
+	waitErr   error
+	stderr    *stderrBuffer
+	log       *slog.Logger
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// ProcessExitError is returned when nmcades exits while a request is in
+// flight. It matches ErrProcessExited with errors.Is.
+type ProcessExitError struct {
+	ExitCode int
+	Err      error
+	Stderr   string
+}
+
+func (e *ProcessExitError) Error() string {
+	message := fmt.Sprintf("%s with code %d", ErrProcessExited, e.ExitCode)
+	if e.Err != nil {
+		message = fmt.Sprintf("%s: %s", message, e.Err)
+	}
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		message = fmt.Sprintf("%s; stderr: %s", message, stderr)
+	}
+	return message
+}
+
+func (e *ProcessExitError) Is(target error) bool {
+	return target == ErrProcessExited
+}
+
+func (e *ProcessExitError) Unwrap() error {
+	return e.Err
 }
 
 func DetermineByteOrder() {
@@ -36,57 +93,145 @@ func DetermineByteOrder() {
 	}
 }
 
-func WriteHeader(writer io.Writer, length int) error {
+// Framer reads and writes native messaging frames: a 4 byte length in native
+// byte order followed by the message.
+type Framer struct {
+	Reader         io.Reader
+	Writer         io.Writer
+	MaxMessageSize uint32
+}
+
+func NewFramer(reader io.Reader, writer io.Writer) *Framer {
+	if nativeEndian == nil {
+		DetermineByteOrder()
+	}
+
+	return &Framer{
+		Reader:         reader,
+		Writer:         writer,
+		MaxMessageSize: DefaultMaxMessageSize,
+	}
+}
+
+// frameBufferSize is the size of the buffer WriteFrameFunc writes through.
+const frameBufferSize = 64 << 10
+
+func (f *Framer) WriteFrame(message []byte) error {
+	if err := f.checkSize(len(message)); err != nil {
+		return err
+	}
+
 	header := make([]byte, 4)
-	nativeEndian.PutUint32(header, (uint32)(length))
+	nativeEndian.PutUint32(header, uint32(len(message)))
 
-	if n, err := writer.Write(header); err != nil || n != len(header) {
+	buffers := net.Buffers{header, message}
+	n, err := buffers.WriteTo(f.Writer)
+	if err != nil {
 		return err
 	}
+	if n != int64(len(header)+len(message)) {
+		return io.ErrShortWrite
+	}
 
 	return nil
 }
 
-func PostMessage(file io.WriteCloser, message []byte) error {
-	var buffer bytes.Buffer
-	writer := bufio.NewWriter(&buffer)
-
-	length := len(message)
+// WriteFrameFunc writes a frame of the given length whose body is produced
+// by write, without holding the whole message in memory.
+func (f *Framer) WriteFrameFunc(length int, write func(w io.Writer) error) error {
+	if err := f.checkSize(length); err != nil {
+		return err
+	}
 
+	writer := bufio.NewWriterSize(f.Writer, frameBufferSize)
 	if err := WriteHeader(writer, length); err != nil {
 		return err
 	}
 
-	if n, err := writer.Write(message); err != nil || n != length {
+	counter := &countingWriter{}
+	if err := write(io.MultiWriter(writer, counter)); err != nil {
 		return err
 	}
+	if counter.n != length {
+		return fmt.Errorf("frame length %d, written %d bytes", length, counter.n)
+	}
 
-	writer.Flush()
-	file.Write(buffer.Bytes())
+	return writer.Flush()
+}
 
+func (f *Framer) checkSize(length int) error {
+	if f.MaxMessageSize > 0 && uint64(length) > uint64(f.MaxMessageSize) {
+		return fmt.Errorf("%w: %d bytes, limit %d", ErrFrameTooLarge, length, f.MaxMessageSize)
+	}
 	return nil
 }
 
-func ReadHeader(stdout io.ReadCloser) (uint32, error) {
-	length := make([]byte, 4)
+func (f *Framer) ReadFrame() ([]byte, error) {
+	length, err := ReadHeader(f.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	if f.MaxMessageSize > 0 && length > f.MaxMessageSize {
+		return nil, fmt.Errorf("%w: %d bytes, limit %d", ErrFrameTooLarge, length, f.MaxMessageSize)
+	}
+
+	data := make([]byte, length)
+	if n, err := io.ReadFull(f.Reader, data); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrShortFrame, length, n)
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
 
-	_, err := stdout.Read(length)
+func WriteHeader(writer io.Writer, length int) error {
+	header := make([]byte, 4)
+	nativeEndian.PutUint32(header, (uint32)(length))
+
+	n, err := writer.Write(header)
 	if err != nil {
-		return nativeEndian.Uint32(length), err
+		return err
+	}
+	if n != len(header) {
+		return io.ErrShortWrite
 	}
 
-	return nativeEndian.Uint32(length), nil
+	return nil
 }
 
-func GetMessageAsBytes(stdout io.ReadCloser) []byte {
-	length, err := ReadHeader(stdout)
-	if err != nil || length == 0 {
-		return []byte{}
+func PostMessage(file io.WriteCloser, message []byte) error {
+	framer := NewFramer(nil, file)
+	framer.MaxMessageSize = 0
+	return framer.WriteFrame(message)
+}
+
+// ReadHeader returns io.EOF if the stream ended before the header and
+// ErrShortFrame if it ended in the middle of it.
+func ReadHeader(stdout io.Reader) (uint32, error) {
+	length := make([]byte, 4)
+
+	n, err := io.ReadFull(stdout, length)
+	if err == io.ErrUnexpectedEOF {
+		return 0, fmt.Errorf("%w: expected 4 bytes of header, got %d", ErrShortFrame, n)
+	}
+	if err != nil {
+		return 0, err
 	}
 
-	data := make([]byte, length)
+	return nativeEndian.Uint32(length), nil
+}
 
-	_, err = stdout.Read(data)
+func ReadMessage(stdout io.Reader) ([]byte, error) {
+	framer := NewFramer(stdout, nil)
+	framer.MaxMessageSize = 0
+	return framer.ReadFrame()
+}
+
+func GetMessageAsBytes(stdout io.ReadCloser) []byte {
+	data, err := ReadMessage(stdout)
 	if err != nil {
 		return []byte{}
 	}
@@ -99,36 +244,255 @@ func GetMessage(stdout io.ReadCloser) string {
 	return string(data)
 }
 
+func (process *CadesProcess) Send(message []byte) error {
+	if err := process.Framer.WriteFrame(message); err != nil {
+		return process.exitError(err)
+	}
+	return nil
+}
+
+func (process *CadesProcess) SendFunc(length int, write func(w io.Writer) error) error {
+	if err := process.Framer.WriteFrameFunc(length, write); err != nil {
+		return process.exitError(err)
+	}
+	return nil
+}
+
+func (process *CadesProcess) Receive() ([]byte, error) {
+	message, err := process.Framer.ReadFrame()
+	if err != nil {
+		return message, process.exitError(err)
+	}
+	return message, nil
+}
+
+func (process *CadesProcess) framer() *Framer {
+	return process.Framer
+}
+
+// Stderr returns the recent lines nmcades wrote to stderr.
+func (process *CadesProcess) Stderr() []string {
+	if process.stderr == nil {
+		return nil
+	}
+	return process.stderr.since(0)
+}
+
+// stderrMark returns the position in stderr to pass to attachStderr.
+func (process *CadesProcess) stderrMark() uint64 {
+	if process == nil || process.stderr == nil {
+		return 0
+	}
+	return process.stderr.mark()
+}
+
+// attachStderr wraps err in a *StderrError with the lines nmcades wrote to
+// stderr after mark. *ProcessExitError already carries stderr and is
+// returned as is.
+func (process *CadesProcess) attachStderr(err error, mark uint64) error {
+	if err == nil || process == nil || process.stderr == nil {
+		return err
+	}
+
+	var exitErr *ProcessExitError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+
+	lines := process.stderr.since(mark)
+	if len(lines) == 0 {
+		return err
+	}
+	return &StderrError{Err: err, Stderr: lines}
+}
+
+func (process *CadesProcess) logger() *slog.Logger {
+	if process.log != nil {
+		return process.log
+	}
+	return slog.Default()
+}
+
+// Exited is closed once the process has exited and was reaped.
+func (process *CadesProcess) Exited() <-chan struct{} {
+	return process.exited
+}
+
+func (process *CadesProcess) watch() {
+	process.waitErr = process.Cmd.Wait()
+	close(process.exited)
+}
+
+// exitError turns a pipe error caused by the exit of nmcades into a
+// *ProcessExitError.
+func (process *CadesProcess) exitError(err error) error {
+	if process.exited == nil {
+		return err
+	}
+
+	select {
+	case <-process.exited:
+	case <-time.After(exitWaitTimeout):
+		return err
+	}
+
+	return process.exitStatus(true)
+}
+
+// exitStatus must be called after the process was reaped. It returns nil for
+// a clean exit unless always is set.
+func (process *CadesProcess) exitStatus(always bool) error {
+	state := process.Cmd.ProcessState
+	if !always && state != nil && state.Success() {
+		return nil
+	}
+
+	exitErr := &ProcessExitError{
+		ExitCode: state.ExitCode(),
+		Err:      process.waitErr,
+	}
+	if process.stderr != nil {
+		exitErr.Stderr = process.stderr.String()
+	}
+	return exitErr
+}
+
+// Close closes stdin of nmcades and waits GracePeriod for it to exit, then
+// kills it. The process is always reaped. An abnormal exit before the kill
+// is returned as *ProcessExitError. Close may be called more than once and
+// from several goroutines.
+func (process *CadesProcess) Close() error {
+	process.closeOnce.Do(func() {
+		process.closeErr = process.shutdown(process.GracePeriod)
+	})
+	return process.closeErr
+}
+
+// Kill stops nmcades without waiting for a clean exit and reaps it.
+func (process *CadesProcess) Kill() error {
+	process.closeOnce.Do(func() {
+		process.closeErr = process.shutdown(0)
+	})
+	return process.closeErr
+}
+
+func (process *CadesProcess) shutdown(gracePeriod time.Duration) error {
+	if process.exited == nil {
+		return process.Cmd.Process.Kill()
+	}
+
+	select {
+	case <-process.exited:
+		return process.exitStatus(false)
+	default:
+	}
+
+	if gracePeriod > 0 {
+		if process.Stdin != nil {
+			(*process.Stdin).Close()
+		}
+
+		timer := time.NewTimer(gracePeriod)
+		defer timer.Stop()
+		select {
+		case <-process.exited:
+			return process.exitStatus(false)
+		case <-timer.C:
+			process.logger().Debug(fmt.Sprintf("[CadesProcess.Close] nmcades did not exit in %s, kill it", gracePeriod))
+		}
+	}
+
+	if err := process.Cmd.Process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+	<-process.exited
+	return nil
+}
+
+// ProcessConfig customizes the nmcades process. Empty Path searches the
+// CryptoPro folders, Env is added to the environment of the current process.
+// Zero GracePeriod means DefaultGracePeriod, a negative one kills nmcades on
+// Close at once. Zero MaxMessageSize means DefaultMaxMessageSize. Start
+// failures, close diagnostics and the lines nmcades writes to stderr are
+// logged to Logger, or to slog.Default() if it is nil.
+type ProcessConfig struct {
+	Path           string
+	Env            []string
+	Dir            string
+	GracePeriod    time.Duration
+	MaxMessageSize uint32
+	Logger         *slog.Logger
+}
+
 func NewNMCadesProcess() (*CadesProcess, error) {
+	return NewNMCadesProcessWithConfig(ProcessConfig{})
+}
+
+func NewNMCadesProcessWithConfig(config ProcessConfig) (*CadesProcess, error) {
 	if nativeEndian == nil {
 		DetermineByteOrder()
 	}
 
-	pathMgr, err := getCryptoProUtilPath("nmcades")
-	if err != nil {
-		return &CadesProcess{}, err
+	pathMgr := config.Path
+	if pathMgr == "" {
+		path, err := getCryptoProUtilPath("nmcades")
+		if err != nil {
+			return &CadesProcess{}, err
+		}
+		pathMgr = path
+	}
+	logger := config.Logger
+	if logger == nil {
+		logger = slog.Default()
 	}
+
 	cmd := exec.Command(pathMgr)
+	cmd.Dir = config.Dir
+	if len(config.Env) > 0 {
+		cmd.Env = append(os.Environ(), config.Env...)
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
-		slog.Error(fmt.Sprintf("Fail connect to StdOut: %s", err))
+		logger.Error(fmt.Sprintf("Fail connect to StdOut: %s", err))
 		return &CadesProcess{}, err
 	}
 
 	stdin, err := cmd.StdinPipe()
 	if err != nil {
-		slog.Error(fmt.Sprintf("Fail connect to StdIn: %s", err))
+		logger.Error(fmt.Sprintf("Fail connect to StdIn: %s", err))
 		return &CadesProcess{}, err
 	}
 
+	stderr := &stderrBuffer{logger: config.Logger}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = stderrWaitDelay
+
 	err = cmd.Start()
 	if err != nil {
-		slog.Error(fmt.Sprintf("Fail start nmcades: %s", err))
+		logger.Error(fmt.Sprintf("Fail start nmcades: %s", err))
 		return &CadesProcess{}, err
 	}
 
-	return &CadesProcess{Cmd: cmd, Stdout: &stdout, Stdin: &stdin}, nil
+	process := &CadesProcess{
+		Cmd:         cmd,
+		Stdout:      &stdout,
+		Stdin:       &stdin,
+		Framer:      NewFramer(stdout, stdin),
+		GracePeriod: config.GracePeriod,
+		exited:      make(chan struct{}),
+		stderr:      stderr,
+		log:         config.Logger,
+	}
+	if process.GracePeriod == 0 {
+		process.GracePeriod = DefaultGracePeriod
+	}
+	if config.MaxMessageSize > 0 {
+		process.Framer.MaxMessageSize = config.MaxMessageSize
+	}
+	go process.watch()
+
+	return process, nil
 }
 
 func getCryptoProUtilPath(filename string) (string, error) {
@@ -0,0 +1,213 @@
+package cades
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+func frame(message string) []byte {
+	var buf bytes.Buffer
+	if err := NewFramer(nil, &buf).WriteFrame([]byte(message)); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestFramerRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	framer := NewFramer(&buf, &buf)
+	if err := framer.WriteFrame([]byte(`{"a":1}`)); err != nil {
+		t.Fatal(err)
+	}
+	err := framer.WriteFrameFunc(7, func(w io.Writer) error {
+		_, err := io.WriteString(w, `{"b":2}`)
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{`{"a":1}`, `{"b":2}`} {
+		message, err := framer.ReadFrame()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(message) != want {
+			t.Errorf("ReadFrame() = %s, want %s", message, want)
+		}
+	}
+	if _, err := framer.ReadFrame(); err != io.EOF {
+		t.Errorf("ReadFrame() at the end of the stream: error = %v, want %v", err, io.EOF)
+	}
+}
+
+func TestFramerShortFrame(t *testing.T) {
+	full := frame(`{"message":"truncated"}`)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"header", full[:2]},
+		{"body", full[:len(full)-3]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewFramer(bytes.NewReader(test.data), nil).ReadFrame()
+			if !errors.Is(err, ErrShortFrame) {
+				t.Errorf("ReadFrame() error = %v, want %v", err, ErrShortFrame)
+			}
+		})
+	}
+}
+
+func TestFramerMaxMessageSize(t *testing.T) {
+	framer := NewFramer(bytes.NewReader(frame("0123456789")), io.Discard)
+	framer.MaxMessageSize = 8
+
+	if _, err := framer.ReadFrame(); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("ReadFrame() error = %v, want %v", err, ErrFrameTooLarge)
+	}
+	if err := framer.WriteFrame([]byte("0123456789")); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("WriteFrame() error = %v, want %v", err, ErrFrameTooLarge)
+	}
+	err := framer.WriteFrameFunc(10, func(w io.Writer) error {
+		t.Error("WriteFrameFunc wrote an oversize frame")
+		return nil
+	})
+	if !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("WriteFrameFunc() error = %v, want %v", err, ErrFrameTooLarge)
+	}
+	if err := framer.WriteFrame([]byte("01234567")); err != nil {
+		t.Errorf("WriteFrame() of a message at the limit: %s", err)
+	}
+}
+
+func TestFramerWriteFrameFuncLength(t *testing.T) {
+	framer := NewFramer(nil, io.Discard)
+	err := framer.WriteFrameFunc(10, func(w io.Writer) error {
+		_, err := io.WriteString(w, "short")
+		return err
+	})
+	if err == nil {
+		t.Error("WriteFrameFunc() with a wrong length: error = nil")
+	}
+}
+
+func TestWithMaxMessageSize(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	emulator := NewEmulator()
+	defer emulator.Close()
+	go serveConn(server, emulator)
+
+	transport := NewConnTransport(client)
+	cades, err := NewCadesWithTransport(context.Background(), transport, WithMaxMessageSize(512))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cades.Close()
+
+	if transport.Framer.MaxMessageSize != 512 {
+		t.Fatalf("MaxMessageSize = %d, want 512", transport.Framer.MaxMessageSize)
+	}
+	_, err = CreateObject(cades, string(bytes.Repeat([]byte("a"), 1024)))
+	if !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("CreateObject() with an oversize request: error = %v, want %v", err, ErrFrameTooLarge)
+	}
+}
+
+// serveConn relays frames between conn and the emulator.
+func serveConn(conn net.Conn, emulator *Emulator) {
+	framer := NewFramer(conn, conn)
+	go func() {
+		for {
+			message, err := emulator.Receive()
+			if err != nil || framer.WriteFrame(message) != nil {
+				return
+			}
+		}
+	}()
+	for {
+		message, err := framer.ReadFrame()
+		if err != nil || emulator.Send(message) != nil {
+			return
+		}
+	}
+}
+
+func TestProcessExitsWithStderrHeld(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs a shell script")
+	}
+
+	// the child of the script keeps stderr open after the script exits
+	path := filepath.Join(t.TempDir(), "nmcades")
+	script := "#!/bin/sh\nsleep 10 >/dev/null &\nexit 3\n"
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	process, err := NewNMCadesProcessWithConfig(ProcessConfig{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-process.Exited():
+	case <-time.After(5 * time.Second):
+		process.Kill()
+		t.Fatal("process was not reaped while a child held its stderr")
+	}
+
+	var exitErr *ProcessExitError
+	if err := process.Close(); !errors.As(err, &exitErr) || exitErr.ExitCode != 3 {
+		t.Errorf("Close() error = %v, want exit code 3", err)
+	}
+}
+
+// logBuffer collects the output of a test logger.
+type logBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *logBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *logBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func testLogger() (*slog.Logger, *logBuffer) {
+	buf := &logBuffer{}
+	return slog.New(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})), buf
+}
+
+func TestProcessLogger(t *testing.T) {
+	logger, logs := testLogger()
+	path := filepath.Join(t.TempDir(), "missing")
+	if _, err := NewNMCadesProcessWithConfig(ProcessConfig{Path: path, Logger: logger}); err == nil {
+		t.Fatal("NewNMCadesProcessWithConfig() of a missing binary: error = nil")
+	}
+	if !strings.Contains(logs.String(), "Fail start nmcades") {
+		t.Errorf("start failure is not logged to the configured logger: %q", logs.String())
+	}
+}
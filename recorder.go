@@ -0,0 +1,250 @@
+package cades
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	RecordSend    = "send"
+	RecordReceive = "receive"
+)
+
+const redactedValue = "***"
+
+// redactedNames are the parts of method and property names whose values are
+// not written to a recording.
+var redactedNames = []string{"pin", "password", "pfx", "secret"}
+
+// RecordedFrame is a line of a recording. RequestId is the request the frame
+// belongs to, callbacks and their answers get the id of the pending request.
+type RecordedFrame struct {
+	Time      time.Time       `json:"time"`
+	Direction string          `json:"direction"`
+	RequestId uint32          `json:"requestid"`
+	Frame     json.RawMessage `json:"frame"`
+}
+
+// Recorder writes the nmcades traffic of a session as JSONL. Values of PINs,
+// passwords and prompt answers are replaced with "***".
+type Recorder struct {
+	mu        sync.Mutex
+	writer    io.Writer
+	sensitive map[uint32]bool
+	err       error
+}
+
+func NewRecorder(writer io.Writer) *Recorder {
+	return &Recorder{
+		writer:    writer,
+		sensitive: make(map[uint32]bool),
+	}
+}
+
+// Err returns the first error of writing the recording.
+func (r *Recorder) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+func (r *Recorder) record(direction string, requestId uint32, message []byte) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	line, err := json.Marshal(RecordedFrame{
+		Time:      time.Now(),
+		Direction: direction,
+		RequestId: requestId,
+		Frame:     r.redact(direction, message),
+	})
+	if err != nil {
+		r.err = err
+		return
+	}
+
+	_, r.err = r.writer.Write(append(line, '\n'))
+}
+
+// redact must be called with r.mu held. Frames that are not JSON objects are
+// recorded as strings.
+func (r *Recorder) redact(direction string, message []byte) json.RawMessage {
+	var body map[string]any
+	if err := json.Unmarshal(message, &body); err != nil {
+		raw, _ := json.Marshal(string(message))
+		return raw
+	}
+
+	data, ok := body["data"].(map[string]any)
+	if !ok {
+		return message
+	}
+
+	requestId, _ := objIdFromValue(data["requestid"])
+	changed := false
+	if direction == RecordSend {
+		name := fmt.Sprint(data["method"], data["get_property"], data["set_property"])
+		isPrompt := data["callback_id"] != nil && strings.Contains(fmt.Sprint(data["value"]), "prompt")
+		if isPrompt || isRedactedName(name) {
+			changed = redactParams(data)
+			if data["get_property"] != nil || data["method"] != nil {
+				r.sensitive[requestId] = true
+			}
+		}
+	} else if r.sensitive[requestId] && data["callback_id"] == nil {
+		delete(r.sensitive, requestId)
+		if retval, ok := data["retval"].(map[string]any); ok && retval["type"] != "object" {
+			retval["value"] = redactedValue
+			changed = true
+		}
+	}
+
+	if !changed {
+		return message
+	}
+	redacted, err := json.Marshal(body)
+	if err != nil {
+		return message
+	}
+	return redacted
+}
+
+func isRedactedName(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range redactedNames {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+func redactParams(data map[string]any) bool {
+	params, ok := data["params"].([]any)
+	if !ok {
+		return false
+	}
+
+	for _, param := range params {
+		if param, ok := param.(map[string]any); ok && param["type"] != "object" {
+			param["value"] = redactedValue
+		}
+	}
+	return true
+}
+
+// ReplayTransport plays back a recording made by Recorder, so a session can
+// be reproduced without CryptoPro. Sent messages must follow the recording:
+// a request with another method or property fails with ErrReplayMismatch.
+// Redacted values are replayed as "***".
+type ReplayTransport struct {
+	mu     sync.Mutex
+	frames []RecordedFrame
+	next   int
+	closed bool
+}
+
+func NewReplayTransport(reader io.Reader) (*ReplayTransport, error) {
+	transport := &ReplayTransport{}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, int(DefaultMaxMessageSize))
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var frame RecordedFrame
+		if err := json.Unmarshal(line, &frame); err != nil {
+			return &ReplayTransport{}, fmt.Errorf("replay: line %d: %w", len(transport.frames)+1, err)
+		}
+		transport.frames = append(transport.frames, frame)
+	}
+	if err := scanner.Err(); err != nil {
+		return &ReplayTransport{}, err
+	}
+
+	return transport, nil
+}
+
+func (t *ReplayTransport) Send(message []byte) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frame, err := t.take(RecordSend)
+	if err != nil {
+		return err
+	}
+
+	if sent, recorded := replayKey(message), replayKey(frame.Frame); sent != recorded {
+		return fmt.Errorf("%w: sent %s, recorded %s", ErrReplayMismatch, sent, recorded)
+	}
+	return nil
+}
+
+func (t *ReplayTransport) Receive() ([]byte, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	frame, err := t.take(RecordReceive)
+	if err != nil {
+		return nil, err
+	}
+
+	var message string
+	if err := json.Unmarshal(frame.Frame, &message); err == nil {
+		return []byte(message), nil
+	}
+	return frame.Frame, nil
+}
+
+func (t *ReplayTransport) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	return nil
+}
+
+// take must be called with t.mu held.
+func (t *ReplayTransport) take(direction string) (*RecordedFrame, error) {
+	if t.closed {
+		return nil, io.ErrClosedPipe
+	}
+	if t.next >= len(t.frames) {
+		return nil, io.EOF
+	}
+
+	frame := &t.frames[t.next]
+	if frame.Direction != direction {
+		return nil, fmt.Errorf("%w: %s at frame %d, recorded %s", ErrReplayMismatch, direction, t.next+1, frame.Direction)
+	}
+	t.next++
+	return frame, nil
+}
+
+// replayKey identifies what a request does, its values may differ from the
+// recording.
+func replayKey(message []byte) string {
+	var body struct {
+		Data *CadesRequestData `json:"data"`
+	}
+	if err := json.Unmarshal(message, &body); err != nil || body.Data == nil {
+		return string(message)
+	}
+
+	data := body.Data
+	if data.CallbackId != 0 {
+		return fmt.Sprintf("answer to callback %d", data.CallbackId)
+	}
+	return fmt.Sprintf("%s objid %d", requestOp(data), data.ObjId)
+}
@@ -0,0 +1,241 @@
+package cades
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+var secretClasses = []*EmulatorClass{
+	testObjectClass,
+	{
+		Name:       "Test.Signer",
+		Properties: map[string]any{"KeyPin": ""},
+	},
+	{
+		Name:       "Test.PrivateKey",
+		Properties: map[string]any{"Pin": ""},
+		Methods: map[string]EmulatorMethod{
+			"ExportPassword": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				return "secret-password", nil
+			},
+		},
+	},
+	{
+		Name: "Test.Prompt",
+		Methods: map[string]EmulatorMethod{
+			"Ask": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				_, err := obj.Emulator.Callback("callback", CallbackPrompt+"('PIN')")
+				return nil, err
+			},
+		},
+	},
+}
+
+// recordSession runs requests that carry secrets through a recorded session.
+func recordSession(t *testing.T, cades *Cades) {
+	t.Helper()
+
+	cades.HandleCallback(CallbackPrompt, func(ctx context.Context, cades *Cades, callback *CallbackData) (*CallbackResult, error) {
+		return &CallbackResult{Params: []CadesParam{{Type: "string", Value: "secret-prompt"}}}, nil
+	})
+
+	object, err := NewDispatchObject(cades, "Test.Object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := object.Set("Value", "visible"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := object.Get("Value"); err != nil || value != "visible" {
+		t.Fatalf("Get(Value) = %v, %v, want visible", value, err)
+	}
+
+	signer, err := NewDispatchObject(cades, "Test.Signer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := signer.Set("KeyPin", "secret-key-pin"); err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := NewDispatchObject(cades, "Test.PrivateKey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := key.Set("Pin", "secret-pin"); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := key.Get("Pin"); err != nil || value != "secret-pin" {
+		t.Fatalf("Get(Pin) = %v, %v, want secret-pin", value, err)
+	}
+	if value, err := key.Call("ExportPassword"); err != nil || value != "secret-password" {
+		t.Fatalf("Call(ExportPassword) = %v, %v, want secret-password", value, err)
+	}
+
+	prompt, err := NewDispatchObject(cades, "Test.Prompt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := prompt.Call("Ask"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRecorderRedact(t *testing.T) {
+	var recording bytes.Buffer
+	recorder := NewRecorder(&recording)
+
+	emulator := NewEmulator()
+	for _, class := range secretClasses {
+		emulator.Register(class)
+	}
+	cades, err := NewCadesWithTransport(context.Background(), emulator, WithRecorder(recorder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cades.Close()
+
+	recordSession(t, cades)
+	if err := recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	output := recording.String()
+	for _, secret := range []string{"secret-key-pin", "secret-pin", "secret-password", "secret-prompt"} {
+		if strings.Contains(output, secret) {
+			t.Errorf("recording contains %q:\n%s", secret, output)
+		}
+	}
+	if !strings.Contains(output, "visible") {
+		t.Errorf("recording lost a value that is not secret:\n%s", output)
+	}
+	if count := strings.Count(output, redactedValue); count < 5 {
+		t.Errorf("recording has %d redacted values, want at least 5:\n%s", count, output)
+	}
+}
+
+func TestRecorderRedactFrames(t *testing.T) {
+	tests := []struct {
+		name      string
+		direction string
+		message   string
+		secret    string
+	}{
+		{"KeyPin setter", RecordSend, `{"data":{"requestid":1,"set_property":"KeyPin","params":[{"type":"string","value":"1111"}]}}`, "1111"},
+		{"Pin setter", RecordSend, `{"data":{"requestid":2,"set_property":"Pin","params":[{"type":"string","value":"2222"}]}}`, "2222"},
+		{"pfx method", RecordSend, `{"data":{"requestid":3,"method":"ImportPfx","params":[{"type":"string","value":"3333"}]}}`, "3333"},
+		{"prompt answer", RecordSend, `{"data":{"callback_id":4,"value":"result = window.prompt('PIN')","params":[{"type":"string","value":"4444"}]}}`, "4444"},
+		{"not a JSON object", RecordReceive, `not json`, ""},
+	}
+
+	recorder := NewRecorder(&bytes.Buffer{})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frame := string(recorder.redact(test.direction, []byte(test.message)))
+			if test.secret != "" && strings.Contains(frame, test.secret) {
+				t.Errorf("redact() = %s, contains %s", frame, test.secret)
+			}
+			if test.secret == "" && frame != `"not json"` {
+				t.Errorf("redact() = %s, want a JSON string", frame)
+			}
+		})
+	}
+
+	// the answer to a sensitive getter is redacted, the answer to another
+	// request with a different id is not
+	recorder.redact(RecordSend, []byte(`{"data":{"requestid":5,"get_property":"Pin"}}`))
+	other := string(recorder.redact(RecordReceive, []byte(`{"data":{"requestid":6,"retval":{"type":"string","value":"5555"}}}`)))
+	if !strings.Contains(other, "5555") {
+		t.Errorf("redact() of an unrelated answer = %s", other)
+	}
+	answer := string(recorder.redact(RecordReceive, []byte(`{"data":{"requestid":5,"retval":{"type":"string","value":"5555"}}}`)))
+	if strings.Contains(answer, "5555") {
+		t.Errorf("redact() of the Pin answer = %s", answer)
+	}
+}
+
+func TestReplayTransport(t *testing.T) {
+	var recording bytes.Buffer
+	emulator := NewEmulator()
+	for _, class := range secretClasses {
+		emulator.Register(class)
+	}
+	recorded, err := NewCadesWithTransport(context.Background(), emulator, WithRecorder(NewRecorder(&recording)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	object, err := NewDispatchObject(recorded, "Test.Object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := object.Set("Value", "visible"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := object.Get("Value"); err != nil {
+		t.Fatal(err)
+	}
+	key, err := NewDispatchObject(recorded, "Test.PrivateKey")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := key.Call("ExportPassword"); err != nil {
+		t.Fatal(err)
+	}
+	recorded.Close()
+
+	t.Run("round trip", func(t *testing.T) {
+		replay, err := NewReplayTransport(bytes.NewReader(recording.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cades, err := NewCadesWithTransport(context.Background(), replay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cades.Close()
+
+		object, err := NewDispatchObject(cades, "Test.Object")
+		if err != nil {
+			t.Fatal(err)
+		}
+		// values sent may differ from the recording
+		if err := object.Set("Value", "other"); err != nil {
+			t.Fatal(err)
+		}
+		if value, err := object.Get("Value"); err != nil || value != "visible" {
+			t.Errorf("Get(Value) = %v, %v, want visible", value, err)
+		}
+		key, err := NewDispatchObject(cades, "Test.PrivateKey")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value, err := key.Call("ExportPassword"); err != nil || value != redactedValue {
+			t.Errorf("Call(ExportPassword) = %v, %v, want %s", value, err, redactedValue)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		replay, err := NewReplayTransport(bytes.NewReader(recording.Bytes()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		cades, err := NewCadesWithTransport(context.Background(), replay)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer cades.Close()
+
+		if _, err := NewDispatchObject(cades, "Test.Signer"); !errors.Is(err, ErrReplayMismatch) {
+			t.Errorf("NewDispatchObject(Test.Signer) error = %v, want ErrReplayMismatch", err)
+		}
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		if _, err := NewReplayTransport(strings.NewReader("{}\nnot json\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+			t.Errorf("NewReplayTransport() error = %v, want line 2", err)
+		}
+	})
+}
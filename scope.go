@@ -0,0 +1,135 @@
+package cades
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Scope records the objects created through a scoped view of a session so
+// they can be released together.
+type Scope struct {
+	// Cades is the scoped view: wrappers created with it, and the objects
+	// they return, are recorded by the scope.
+	Cades *Cades
+
+	mu      sync.Mutex
+	objects []CadesObject
+}
+
+// NewScope returns a scope over the session. The view shares the nmcades
+// process and the lock with the session.
+func (cades *Cades) NewScope() *Scope {
+	scope := &Scope{}
+	scope.Cades = &Cades{
+		Id:     cades.Id,
+		parent: cades,
+		scope:  scope,
+	}
+	return scope
+}
+
+// WithScope runs f with a scoped view of the session and releases every
+// object created inside f when it returns.
+func (cades *Cades) WithScope(f func(scoped *Cades) error) error {
+	scope := cades.NewScope()
+	err := f(scope.Cades)
+	if releaseErr := scope.Release(); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+// withScopeContext is WithScope whose requests are sent with ctx, including
+// those of wrappers that take no context. The objects are released with a
+// background context, so they are freed even if ctx is done. The context of
+// a callback handler keeps its hold of the session lock.
+func (cades *Cades) withScopeContext(ctx context.Context, f func(scoped *Cades) error) error {
+	scope := cades.NewScope()
+	scope.Cades.ctx = ctx
+	err := f(scope.Cades)
+	scope.Cades.ctx = nil
+
+	releaseCtx := context.Background()
+	if held := ctx.Value(sessionLockKey{}); held != nil {
+		releaseCtx = context.WithValue(releaseCtx, sessionLockKey{}, held)
+	}
+	if releaseErr := scope.ReleaseContext(releaseCtx); err == nil {
+		err = releaseErr
+	}
+	return err
+}
+
+func (scope *Scope) add(objId uint32, generation uint32) {
+	scope.mu.Lock()
+	defer scope.mu.Unlock()
+	scope.objects = append(scope.objects, CadesObject{Cades: scope.Cades, ObjId: objId, Generation: generation})
+}
+
+// Release releases the recorded objects that are still alive, newest first.
+func (scope *Scope) Release() error {
+	return scope.ReleaseContext(context.Background())
+}
+
+// ReleaseContext is Release with a context. A callback handler must pass
+// its context, the session lock is held by the request being answered.
+func (scope *Scope) ReleaseContext(ctx context.Context) error {
+	scope.mu.Lock()
+	objects := scope.objects
+	scope.objects = nil
+	scope.mu.Unlock()
+
+	var errs []error
+	for i := len(objects) - 1; i >= 0; i-- {
+		obj := objects[i]
+		if !scope.Cades.isLive(&obj) {
+			continue
+		}
+		if err := ReleaseObjectContext(ctx, &obj); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func ReleaseObject(c *CadesObject) error {
+	return ReleaseObjectContext(context.Background(), c)
+}
+
+// ReleaseObjectContext asks nmcades to free the object. The handle must not be
+// used afterwards.
+func ReleaseObjectContext(ctx context.Context, c *CadesObject) error {
+	body := &CadesRequestBody{
+		Data: &CadesRequestData{
+			ObjId:       c.ObjId,
+			Destination: "nmcades",
+			Type:        "release",
+		},
+	}
+
+	_, err := c.Cades.sendRequest(ctx, body, c)
+	return err
+}
+
+// LiveObjects returns the number of objects created in the current nmcades
+// process and not released yet. It does not wait for pending requests.
+func (cades *Cades) LiveObjects() int {
+	if cades.parent != nil {
+		return cades.parent.LiveObjects()
+	}
+
+	cades.objectsMu.Lock()
+	defer cades.objectsMu.Unlock()
+	return len(cades.objects)
+}
+
+func (cades *Cades) isLive(obj *CadesObject) bool {
+	if cades.parent != nil {
+		return cades.parent.isLive(obj)
+	}
+
+	cades.objectsMu.Lock()
+	defer cades.objectsMu.Unlock()
+	_, ok := cades.objects[obj.ObjId]
+	return ok && obj.Generation == cades.Generation
+}
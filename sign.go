@@ -0,0 +1,215 @@
+package cades
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SignRequest describes a signature made by Cades.Sign. The content is taken
+// from Data or, if it is set, from Reader.
+type SignRequest struct {
+	// Thumbprint is the SHA1 hash of a certificate in the personal store of
+	// the current user, spaces are ignored.
+	Thumbprint string
+	Data       []byte
+	Reader     io.Reader
+	Detached   bool
+	// Type is one of CADESCOM_CADES_BES (used if zero), CADESCOM_CADES_T,
+	// CADESCOM_CADES_X_LONG_TYPE_1 or CADESCOM_PKCS7_TYPE.
+	Type int
+	// PIN of the key container, if empty nmcades asks for it with a callback.
+	PIN string
+	// TSA is the address of the time-stamp service for CADESCOM_CADES_T and
+	// CADESCOM_CADES_X_LONG_TYPE_1.
+	TSA        string
+	Attributes []SignAttribute
+}
+
+// SignAttribute is an authenticated attribute of the signature. Name is one
+// of CADESCOM_AUTHENTICATED_ATTRIBUTE_*, an attribute with OID set is added
+// by OID. Value is a string or a time.Time.
+type SignAttribute struct {
+	Name  int
+	OID   string
+	Value any
+}
+
+// Sign signs the content with the certificate found by thumbprint in the
+// CAPICOM_MY_STORE store and returns the DER encoded signature and the
+// certificate. Every object created for it is released. If ctx is done
+// before nmcades answers, the session is closed, see SendRequestContext.
+func (cades *Cades) Sign(ctx context.Context, request SignRequest) ([]byte, *x509.Certificate, error) {
+	content, err := signContent(&request)
+	if err != nil {
+		return nil, &x509.Certificate{}, err
+	}
+
+	var (
+		signature   []byte
+		certificate *x509.Certificate
+	)
+	err = cades.withScopeContext(ctx, func(scoped *Cades) error {
+		cert, err := findCertificate(scoped, request.Thumbprint)
+		if err != nil {
+			return err
+		}
+
+		signer, err := newSigner(scoped, cert, &request)
+		if err != nil {
+			return err
+		}
+
+		signedData, err := NewCadesSignedData(scoped)
+		if err != nil {
+			return err
+		}
+		if _, err := signedData.SetContentEncoding(CADESCOM_BASE64_TO_BINARY); err != nil {
+			return err
+		}
+		if _, err := signedData.SetContent(content); err != nil {
+			return err
+		}
+
+		cadesType := request.Type
+		if cadesType == 0 {
+			cadesType = CADESCOM_CADES_BES
+		}
+		value, err := signedData.SignCades(signer, cadesType, request.Detached, CADESCOM_ENCODE_BASE64)
+		if err != nil {
+			return err
+		}
+		if signature, err = decodeBase64(value); err != nil {
+			return fmt.Errorf("decode signature: %w", err)
+		}
+
+		certificate, err = cert.X509()
+		return err
+	})
+	if err != nil {
+		return nil, &x509.Certificate{}, err
+	}
+
+	return signature, certificate, nil
+}
+
+func signContent(request *SignRequest) (string, error) {
+	if request.Thumbprint == "" {
+		return "", errors.New("sign: thumbprint is required")
+	}
+	if request.Reader == nil {
+		return base64.StdEncoding.EncodeToString(request.Data), nil
+	}
+	if request.Data != nil {
+		return "", errors.New("sign: both Data and Reader are set")
+	}
+
+	data, err := io.ReadAll(request.Reader)
+	if err != nil {
+		return "", fmt.Errorf("sign: read content: %w", err)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// findCertificate opens the personal store of the current user and returns
+// the certificate with the given SHA1 thumbprint.
+func findCertificate(cades *Cades, thumbprint string) (*Certificate, error) {
+	thumbprint = strings.ReplaceAll(thumbprint, " ", "")
+
+	store, err := NewStore(cades)
+	if err != nil {
+		return &Certificate{}, err
+	}
+	if err := store.Open(CAPICOM_CURRENT_USER_STORE, CAPICOM_MY_STORE); err != nil {
+		return &Certificate{}, err
+	}
+	defer store.Close()
+
+	certificates, err := store.Certificates()
+	if err != nil {
+		return &Certificate{}, err
+	}
+
+	found, err := certificates.Find(CAPICOM_CERTIFICATE_FIND_SHA1_HASH, thumbprint)
+	if err != nil {
+		return &Certificate{}, err
+	}
+
+	count, err := found.Count()
+	if err != nil {
+		return &Certificate{}, err
+	}
+	if count == 0 {
+		return &Certificate{}, fmt.Errorf("%w: %s", ErrCertificateNotExists, thumbprint)
+	}
+
+	return found.Item(1)
+}
+
+func newSigner(cades *Cades, certificate *Certificate, request *SignRequest) (*CPSigner, error) {
+	signer, err := NewCPSigner(cades)
+	if err != nil {
+		return &CPSigner{}, err
+	}
+
+	if _, err := signer.SetCertificate(certificate); err != nil {
+		return &CPSigner{}, err
+	}
+	if request.PIN != "" {
+		if _, err := signer.SetKeyPin(request.PIN); err != nil {
+			return &CPSigner{}, err
+		}
+	}
+	if request.TSA != "" {
+		if _, err := signer.SetTSAAddress(request.TSA); err != nil {
+			return &CPSigner{}, err
+		}
+	}
+
+	if len(request.Attributes) == 0 {
+		return signer, nil
+	}
+	attributes, err := signer.AuthenticatedAttributes2()
+	if err != nil {
+		return &CPSigner{}, err
+	}
+	for _, attribute := range request.Attributes {
+		if err := addAttribute(cades, attributes, &attribute); err != nil {
+			return &CPSigner{}, err
+		}
+	}
+
+	return signer, nil
+}
+
+func addAttribute(cades *Cades, attributes *CPAttributes, attribute *SignAttribute) error {
+	cpAttribute, err := NewCPAttribute(cades)
+	if err != nil {
+		return err
+	}
+
+	if attribute.OID != "" {
+		_, err = cpAttribute.SetOID(attribute.OID)
+	} else {
+		_, err = cpAttribute.SetName(attribute.Name)
+	}
+	if err != nil {
+		return err
+	}
+	if _, err := cpAttribute.SetValue(attribute.Value); err != nil {
+		return err
+	}
+
+	return attributes.Add(cpAttribute)
+}
+
+// decodeBase64 decodes the base64 returned by the plugin, which may be split
+// into lines.
+func decodeBase64(value string) ([]byte, error) {
+	value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+	return base64.StdEncoding.DecodeString(value)
+}
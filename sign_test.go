@@ -0,0 +1,280 @@
+package cades
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// emulatedSignature is what SignCades of the emulator was called with.
+type emulatedSignature struct {
+	Thumbprint string
+	KeyPin     string
+	TSAAddress string
+	Attributes []map[string]any
+	Type       float64
+	Detached   bool
+}
+
+// signClasses emulate the classes Sign uses. The personal store holds a
+// certificate with the thumbprint AABBCC and the certificate der, SignCades
+// stores its arguments in signature and signs the content as "signed:" and
+// the content.
+func signClasses(der string, signature *emulatedSignature) []*EmulatorClass {
+	return []*EmulatorClass{
+		{
+			Name:       "CAdESCOM.Store",
+			Properties: map[string]any{"Certificates": errors.New("store is not open")},
+			Methods: map[string]EmulatorMethod{
+				"Open": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					certificate, err := obj.Emulator.NewObject("CAdESCOM.Certificate")
+					if err != nil {
+						return nil, err
+					}
+					certificate.Properties["Thumbprint"] = "AABBCC"
+					certificate.Properties["der"] = der
+
+					certificates, err := newEmulatedCertificates(obj.Emulator, []*EmulatorObject{certificate})
+					if err != nil {
+						return nil, err
+					}
+					obj.Properties["Certificates"] = certificates
+					return nil, nil
+				},
+				"Close": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					return nil, nil
+				},
+			},
+		},
+		{
+			Name:       "CAdESCOM.Certificates",
+			Properties: map[string]any{"Count": 0},
+			Methods: map[string]EmulatorMethod{
+				"Item": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					items := obj.Properties["items"].([]*EmulatorObject)
+					index, _ := params[0].Value.(float64)
+					if index < 1 || int(index) > len(items) {
+						return nil, fmt.Errorf("invalid index %v", params[0].Value)
+					}
+					return items[int(index)-1], nil
+				},
+				"Find": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					if findType, _ := params[0].Value.(float64); findType != CAPICOM_CERTIFICATE_FIND_SHA1_HASH {
+						return nil, fmt.Errorf("unsupported find type %v", params[0].Value)
+					}
+					var found []*EmulatorObject
+					for _, item := range obj.Properties["items"].([]*EmulatorObject) {
+						if strings.EqualFold(fmt.Sprint(item.Properties["Thumbprint"]), fmt.Sprint(params[1].Value)) {
+							found = append(found, item)
+						}
+					}
+					return newEmulatedCertificates(obj.Emulator, found)
+				},
+			},
+		},
+		{
+			Name: "CAdESCOM.Certificate",
+			Methods: map[string]EmulatorMethod{
+				"Export": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					return obj.Properties["der"], nil
+				},
+			},
+		},
+		{
+			Name: "CAdESCOM.CPSigner",
+			Properties: map[string]any{
+				"Certificate":              errors.New("certificate is not set"),
+				"KeyPin":                   "",
+				"TSAAddress":               "",
+				"AuthenticatedAttributes2": errors.New("attributes are not available"),
+			},
+		},
+		{
+			Name:       "CAdESCOM.CPAttributes",
+			Properties: map[string]any{"Count": 0},
+			Methods: map[string]EmulatorMethod{
+				"Add": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					attribute, ok := obj.Emulator.paramValue(params[0]).(*EmulatorObject)
+					if !ok {
+						return nil, fmt.Errorf("invalid attribute %v", params[0].Value)
+					}
+					items, _ := obj.Properties["items"].([]*EmulatorObject)
+					obj.Properties["items"] = append(items, attribute)
+					obj.Properties["Count"] = len(items) + 1
+					return nil, nil
+				},
+			},
+		},
+		{
+			Name:       "CAdESCOM.CPAttribute",
+			Properties: map[string]any{"Name": 0, "OID": "", "Value": ""},
+		},
+		{
+			Name:       "CAdESCOM.CadesSignedData",
+			Properties: map[string]any{"Content": "", "ContentEncoding": 0},
+			Methods: map[string]EmulatorMethod{
+				"SignCades": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					signer, ok := obj.Emulator.paramValue(params[0]).(*EmulatorObject)
+					if !ok {
+						return nil, fmt.Errorf("invalid signer %v", params[0].Value)
+					}
+					certificate, ok := signer.Properties["Certificate"].(*EmulatorObject)
+					if !ok {
+						return nil, errors.New("certificate is not set")
+					}
+
+					*signature = emulatedSignature{
+						Thumbprint: fmt.Sprint(certificate.Properties["Thumbprint"]),
+						KeyPin:     fmt.Sprint(signer.Properties["KeyPin"]),
+						TSAAddress: fmt.Sprint(signer.Properties["TSAAddress"]),
+					}
+					if attributes, ok := signer.Properties["AuthenticatedAttributes2"].(*EmulatorObject); ok {
+						items, _ := attributes.Properties["items"].([]*EmulatorObject)
+						for _, item := range items {
+							signature.Attributes = append(signature.Attributes, item.Properties)
+						}
+					}
+					signature.Type, _ = params[1].Value.(float64)
+					signature.Detached, _ = params[2].Value.(bool)
+
+					content, err := base64.StdEncoding.DecodeString(fmt.Sprint(obj.Properties["Content"]))
+					if err != nil {
+						return nil, err
+					}
+					return base64.StdEncoding.EncodeToString(append([]byte("signed:"), content...)), nil
+				},
+			},
+		},
+	}
+}
+
+func newEmulatedCertificates(emulator *Emulator, items []*EmulatorObject) (*EmulatorObject, error) {
+	certificates, err := emulator.NewObject("CAdESCOM.Certificates")
+	if err != nil {
+		return nil, err
+	}
+	certificates.Properties["Count"] = len(items)
+	certificates.Properties["items"] = items
+	return certificates, nil
+}
+
+// setSignerAttributes gives the next CPSigner an empty attribute collection,
+// it is released with the scope of Sign.
+func setSignerAttributes(t *testing.T, emulator *Emulator, signer *EmulatorClass) {
+	t.Helper()
+
+	attributes, err := emulator.NewObject("CAdESCOM.CPAttributes")
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer.Properties["AuthenticatedAttributes2"] = attributes
+}
+
+func TestSign(t *testing.T) {
+	var signature emulatedSignature
+	classes := signClasses(testCertificate(t), &signature)
+	cades, emulator := newEmulatorCades(t, classes...)
+
+	signingTime := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	setSignerAttributes(t, emulator, classes[3])
+	value, certificate, err := cades.Sign(context.Background(), SignRequest{
+		Thumbprint: "aa bb cc",
+		Data:       []byte("content"),
+		Detached:   true,
+		Type:       CADESCOM_CADES_T,
+		PIN:        "1234",
+		TSA:        "http://tsa.example/tsp.srf",
+		Attributes: []SignAttribute{
+			{Name: CADESCOM_AUTHENTICATED_ATTRIBUTE_DOCUMENT_NAME, Value: "contract.pdf"},
+			{OID: "1.2.643.100.1", Value: signingTime},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "signed:content" {
+		t.Errorf("Sign() = %q, want signed:content", value)
+	}
+	if certificate == nil || certificate.Subject.CommonName != "signer" {
+		t.Errorf("Sign() certificate = %v", certificate)
+	}
+
+	if signature.Thumbprint != "AABBCC" || signature.KeyPin != "1234" || signature.TSAAddress != "http://tsa.example/tsp.srf" {
+		t.Errorf("signer = %+v", signature)
+	}
+	if signature.Type != CADESCOM_CADES_T || !signature.Detached {
+		t.Errorf("SignCades type %v, detached %t, want CADESCOM_CADES_T, detached", signature.Type, signature.Detached)
+	}
+	if len(signature.Attributes) != 2 {
+		t.Fatalf("attributes = %v, want 2", signature.Attributes)
+	}
+	if name := signature.Attributes[0]; name["Name"] != float64(CADESCOM_AUTHENTICATED_ATTRIBUTE_DOCUMENT_NAME) || name["Value"] != "contract.pdf" {
+		t.Errorf("attributes[0] = %v", name)
+	}
+	if oid := signature.Attributes[1]; oid["OID"] != "1.2.643.100.1" || oid["Value"] != DateToUTCStr(signingTime) {
+		t.Errorf("attributes[1] = %v", oid)
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() = %d, want 0", live)
+	}
+}
+
+func TestSignReader(t *testing.T) {
+	var signature emulatedSignature
+	cades, emulator := newEmulatorCades(t, signClasses(testCertificate(t), &signature)...)
+
+	value, _, err := cades.Sign(context.Background(), SignRequest{
+		Thumbprint: "AABBCC",
+		Reader:     strings.NewReader("from reader"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(value) != "signed:from reader" {
+		t.Errorf("Sign() = %q, want signed:from reader", value)
+	}
+	if signature.Type != CADESCOM_CADES_BES || signature.Detached || len(signature.Attributes) != 0 {
+		t.Errorf("SignCades = %+v, want an attached CAdES-BES", signature)
+	}
+
+	// an empty PIN and TSA are not set, nmcades asks for the PIN itself
+	for _, request := range emulator.Requests() {
+		if request.SetProperty == "KeyPin" || request.SetProperty == "TSAAddress" {
+			t.Errorf("Sign() set %s", request.SetProperty)
+		}
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() = %d, want 0", live)
+	}
+}
+
+func TestSignErrors(t *testing.T) {
+	var signature emulatedSignature
+	cades, emulator := newEmulatorCades(t, signClasses(testCertificate(t), &signature)...)
+
+	_, _, err := cades.Sign(context.Background(), SignRequest{Thumbprint: "DDEEFF", Data: []byte("content")})
+	if !errors.Is(err, ErrCertificateNotExists) {
+		t.Errorf("Sign() of a missing certificate: error = %v, want ErrCertificateNotExists", err)
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() = %d, want 0", live)
+	}
+
+	requests := len(emulator.Requests())
+	for _, request := range []SignRequest{
+		{Data: []byte("content")},
+		{Thumbprint: "AABBCC", Data: []byte("content"), Reader: bytes.NewReader([]byte("content"))},
+	} {
+		if _, _, err := cades.Sign(context.Background(), request); err == nil || !strings.HasPrefix(err.Error(), "sign: ") {
+			t.Errorf("Sign(%+v) error = %v", request, err)
+		}
+	}
+	if sent := len(emulator.Requests()) - requests; sent != 0 {
+		t.Errorf("invalid requests sent %d requests, want 0", sent)
+	}
+}
@@ -0,0 +1,121 @@
+package cades
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/exp/slog"
+)
+
+const (
+	// stderrLines is how many recent lines of the nmcades stderr are kept.
+	stderrLines = 64
+	// stderrLineSize cuts longer lines.
+	stderrLineSize = 1024
+)
+
+// StderrError adds the lines nmcades wrote to stderr to an error.
+type StderrError struct {
+	Err    error
+	Stderr []string
+}
+
+func (e *StderrError) Error() string {
+	return fmt.Sprintf("%s; stderr: %s", e.Err, strings.Join(e.Stderr, "\n"))
+}
+
+func (e *StderrError) Unwrap() error {
+	return e.Err
+}
+
+// stderrBuffer keeps the recent lines nmcades writes to stderr and forwards
+// them to the logger. Lines are numbered, so an error can carry only the
+// lines written while the failed request was running.
+type stderrBuffer struct {
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	lines   []string
+	count   uint64
+	partial []byte
+}
+
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			b.appendPartial(p)
+			break
+		}
+		b.appendPartial(p[:i])
+		b.addLine()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+func (b *stderrBuffer) appendPartial(p []byte) {
+	if free := stderrLineSize - len(b.partial); len(p) > free {
+		p = p[:free]
+	}
+	b.partial = append(b.partial, p...)
+}
+
+func (b *stderrBuffer) addLine() {
+	line := strings.TrimRight(string(b.partial), "\r")
+	b.partial = b.partial[:0]
+	if strings.TrimSpace(line) == "" {
+		return
+	}
+
+	logger := b.logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+	logger.Warn(fmt.Sprintf("[nmcades] stderr: %s", line))
+
+	if len(b.lines) < stderrLines {
+		b.lines = append(b.lines, line)
+	} else {
+		b.lines[b.count%stderrLines] = line
+	}
+	b.count++
+}
+
+// mark returns the number of lines written so far.
+func (b *stderrBuffer) mark() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.count
+}
+
+// since returns the kept lines written after mark, including the unfinished
+// last line.
+func (b *stderrBuffer) since(mark uint64) []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	first := b.count - uint64(len(b.lines))
+	if mark > first {
+		first = mark
+	}
+
+	var lines []string
+	for i := first; i < b.count; i++ {
+		lines = append(lines, b.lines[i%stderrLines])
+	}
+	if line := strings.TrimSpace(string(b.partial)); line != "" {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func (b *stderrBuffer) String() string {
+	return strings.Join(b.since(0), "\n")
+}
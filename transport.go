@@ -0,0 +1,73 @@
+package cades
+
+import (
+	"io"
+	"net"
+)
+
+// Transport delivers nmcades messages. Send and Receive exchange whole
+// messages without the length header, Close releases the connection and
+// unblocks a pending Receive. Send must not keep message after it returns.
+type Transport interface {
+	Send(message []byte) error
+	Receive() ([]byte, error)
+	Close() error
+}
+
+// FuncSender is implemented by transports that can write a message of a
+// known length straight to the connection. Cades uses it to send large
+// content without building the message in memory.
+type FuncSender interface {
+	SendFunc(length int, write func(w io.Writer) error) error
+}
+
+// framedTransport is implemented by transports that read and write through
+// a Framer, the session sets its maximum message size.
+type framedTransport interface {
+	framer() *Framer
+}
+
+type ConnTransport struct {
+	Conn   io.ReadWriteCloser
+	Framer *Framer
+}
+
+// NewConnTransport returns a transport that speaks the native messaging
+// framing over conn, for example nmcades exposed on a socket.
+func NewConnTransport(conn io.ReadWriteCloser) *ConnTransport {
+	return &ConnTransport{
+		Conn:   conn,
+		Framer: NewFramer(conn, conn),
+	}
+}
+
+// DialTransport connects to nmcades listening on the given address,
+// e.g. DialTransport("unix", "/run/nmcades.sock").
+func DialTransport(network string, address string) (*ConnTransport, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return &ConnTransport{}, err
+	}
+
+	return NewConnTransport(conn), nil
+}
+
+func (t *ConnTransport) Send(message []byte) error {
+	return t.Framer.WriteFrame(message)
+}
+
+func (t *ConnTransport) SendFunc(length int, write func(w io.Writer) error) error {
+	return t.Framer.WriteFrameFunc(length, write)
+}
+
+func (t *ConnTransport) Receive() ([]byte, error) {
+	return t.Framer.ReadFrame()
+}
+
+func (t *ConnTransport) framer() *Framer {
+	return t.Framer
+}
+
+func (t *ConnTransport) Close() error {
+	return t.Conn.Close()
+}
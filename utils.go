@@ -1,6 +1,7 @@
 package cades
 
 import (
+	"context"
 	"crypto/sha1"
 	"crypto/x509"
 	"encoding/asn1"
@@ -132,6 +133,14 @@ func DateToUTCStr(d time.Time) string {
 	return d.UTC().Format("2006-01-02T15:04:05.999Z")
 }
 
+// cadesObjectWrapper is implemented by *CadesObject, *DispatchObject and the
+// typed wrappers such as *Certificate or *CPSigner.
+type cadesObjectWrapper interface {
+	cadesObject() *CadesObject
+}
+
+// ValueToParam converts strings, numbers, bool, time.Time and objects, given
+// as CadesObject or any wrapper, to a CadesParam.
 func ValueToParam(value any) *CadesParam {
 	var paramType string
 	paramValue := value
@@ -143,8 +152,10 @@ func ValueToParam(value any) *CadesParam {
 	} else if _, ok := value.(bool); ok {
 		paramType = "boolean"
 	} else if cObj, ok := value.(CadesObject); ok {
-		paramType = "object"
-		paramValue = cObj.ObjId
+		return &CadesParam{Type: "object", Value: cObj.ObjId, generation: cObj.Generation}
+	} else if wrapper, ok := value.(cadesObjectWrapper); ok && wrapper.cadesObject() != nil {
+		cObj := wrapper.cadesObject()
+		return &CadesParam{Type: "object", Value: cObj.ObjId, generation: cObj.Generation}
 	} else {
 		paramType = "number"
 	}
@@ -166,18 +177,20 @@ func ArgumentsToParams(maxArgs uint8, args []any) []CadesParam {
 }
 
 func GetProperty[T any](c *CadesObject, name string) (T, error) {
+	return GetPropertyContext[T](context.Background(), c, name)
+}
+
+func GetPropertyContext[T any](ctx context.Context, c *CadesObject, name string) (T, error) {
 	defaultValue := DefaultTypeValue[T]{}.Value
 	body := &CadesRequestBody{
-		Tabid: c.Cades.Id,
 		Data: &CadesRequestData{
-			RequestId:   c.Cades.RequestId,
 			ObjId:       c.ObjId,
 			Destination: "nmcades",
 			GetProperty: name,
 			// Property:    name,
 		},
 	}
-	data, err := c.Cades.SendRequest(body)
+	data, err := c.Cades.sendRequest(ctx, body, c)
 	if err != nil {
 		return defaultValue, err
 
@@ -191,11 +204,13 @@ func GetProperty[T any](c *CadesObject, name string) (T, error) {
 }
 
 func SetProperty(c *CadesObject, name string, params []CadesParam) (bool, error) {
+	return SetPropertyContext(context.Background(), c, name, params)
+}
+
+func SetPropertyContext(ctx context.Context, c *CadesObject, name string, params []CadesParam) (bool, error) {
 	defaultValue := false
 	body := &CadesRequestBody{
-		Tabid: c.Cades.Id,
 		Data: &CadesRequestData{
-			RequestId:   c.Cades.RequestId,
 			ObjId:       c.ObjId,
 			Destination: "nmcades",
 			SetProperty: name,
@@ -203,7 +218,7 @@ func SetProperty(c *CadesObject, name string, params []CadesParam) (bool, error)
 		},
 	}
 
-	data, err := c.Cades.SendRequest(body)
+	data, err := c.Cades.sendRequest(ctx, body, c)
 	if err != nil {
 		return defaultValue, err
 
@@ -220,35 +235,68 @@ func SetProperty(c *CadesObject, name string, params []CadesParam) (bool, error)
 func GetPropertyWithObject(c *CadesObject, name string) (*CadesObject, error) {
 	defaultValue := DefaultTypeValue[CadesObject]{}.Value
 	body := &CadesRequestBody{
-		Tabid: c.Cades.Id,
 		Data: &CadesRequestData{
-			RequestId:   c.Cades.RequestId,
 			ObjId:       c.ObjId,
 			Destination: "nmcades",
 			GetProperty: name,
 		},
 	}
 
-	data, err := c.Cades.SendRequest(body)
+	data, err := c.Cades.sendRequest(context.Background(), body, c)
 	if err != nil {
 		return &defaultValue, err
 	}
 
-	if data.ReturnValue.Type == "object" {
-		c.Cades.ObjId++
-		defaultValue.Cades = c.Cades
-		defaultValue.ObjId = c.Cades.ObjId
-		return &defaultValue, nil
+	return objectFromResponse(c.Cades, data)
+}
+
+func CreateObject(cades *Cades, name string) (*CadesObject, error) {
+	return CreateObjectContext(context.Background(), cades, name)
+}
+
+func CreateObjectContext(ctx context.Context, cades *Cades, name string) (*CadesObject, error) {
+	body := &CadesRequestBody{
+		Data: &CadesRequestData{
+			Destination: "nmcades",
+			Method:      "CreateObject",
+			Params: []CadesParam{
+				{Type: "string", Value: name},
+			},
+		},
 	}
 
-	return &defaultValue, ErrEmpty
+	data, err := cades.SendRequestContext(ctx, body)
+	if err != nil {
+		return &CadesObject{}, err
+	}
+
+	return objectFromResponse(cades, data)
+}
+
+func CallMethodWithObject(c *CadesObject, name string, params []CadesParam) (*CadesObject, error) {
+	data, err := CallMethod(c, name, params)
+	if err != nil {
+		return &CadesObject{}, err
+	}
+
+	return objectFromResponse(c.Cades, data)
+}
+
+func objectFromResponse(cades *Cades, data *CadesResponseData) (*CadesObject, error) {
+	if data.ObjId == 0 {
+		return &CadesObject{}, ErrEmpty
+	}
+
+	return &CadesObject{Cades: cades, ObjId: data.ObjId, Generation: data.Generation}, nil
 }
 
 func CallMethod(c *CadesObject, name string, params []CadesParam) (*CadesResponseData, error) {
+	return CallMethodContext(context.Background(), c, name, params)
+}
+
+func CallMethodContext(ctx context.Context, c *CadesObject, name string, params []CadesParam) (*CadesResponseData, error) {
 	body := &CadesRequestBody{
-		Tabid: c.Cades.Id,
 		Data: &CadesRequestData{
-			RequestId:   c.Cades.RequestId,
 			ObjId:       c.ObjId,
 			Destination: "nmcades",
 			Method:      name,
@@ -256,7 +304,7 @@ func CallMethod(c *CadesObject, name string, params []CadesParam) (*CadesRespons
 		},
 	}
 
-	data, err := c.Cades.SendRequest(body)
+	data, err := c.Cades.sendRequest(ctx, body, c)
 	if err != nil {
 		return data, err
 	}
@@ -0,0 +1,63 @@
+package cades
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValueToParam(t *testing.T) {
+	obj := CadesObject{ObjId: 7, Generation: 2}
+	tests := []struct {
+		name  string
+		value any
+		want  CadesParam
+	}{
+		{"string", "My", CadesParam{Type: "string", Value: "My"}},
+		{"number", 2, CadesParam{Type: "number", Value: 2}},
+		{"bool", true, CadesParam{Type: "boolean", Value: true}},
+		{"time", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), CadesParam{Type: "string", Value: "2024-01-02T03:04:05Z"}},
+		{"CadesObject", obj, CadesParam{Type: "object", Value: uint32(7), generation: 2}},
+		{"*CadesObject", &obj, CadesParam{Type: "object", Value: uint32(7), generation: 2}},
+		{"*DispatchObject", AsDispatchObject(&obj), CadesParam{Type: "object", Value: uint32(7), generation: 2}},
+		{"*Certificate", (*Certificate)(&obj), CadesParam{Type: "object", Value: uint32(7), generation: 2}},
+		{"*CPSigner", (*CPSigner)(&obj), CadesParam{Type: "object", Value: uint32(7), generation: 2}},
+		{"*Store", (*Store)(&obj), CadesParam{Type: "object", Value: uint32(7), generation: 2}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ValueToParam(test.value); *got != test.want {
+				t.Errorf("ValueToParam() = %+v, want %+v", *got, test.want)
+			}
+		})
+	}
+}
+
+func TestDispatchWrapperParam(t *testing.T) {
+	cades, emulator := newEmulatorCades(t, testObjectClass, &EmulatorClass{
+		Name: "Test.Holder",
+		Methods: map[string]EmulatorMethod{
+			"Hold": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+				return nil, nil
+			},
+		},
+	})
+
+	held, err := NewDispatchObject(cades, "Test.Object")
+	if err != nil {
+		t.Fatal(err)
+	}
+	holder, err := NewDispatchObject(cades, "Test.Holder")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := holder.Call("Hold", (*Certificate)(&held.CadesObject)); err != nil {
+		t.Fatal(err)
+	}
+
+	requests := emulator.Requests()
+	params := requests[len(requests)-1].Params
+	if len(params) != 1 || params[0].Type != "object" || params[0].Value != float64(held.ObjId) {
+		t.Errorf("Hold params = %+v, want object %d", params, held.ObjId)
+	}
+}
@@ -0,0 +1,383 @@
+package cades
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// VerifyRequest describes a signature checked by Cades.Verify. The content
+// of a detached signature is taken from Data or, if it is set, from Reader.
+// It is ignored for an attached signature.
+type VerifyRequest struct {
+	Signature []byte
+	Data      []byte
+	Reader    io.Reader
+	// Type is the signature type nmcades expects, CADESCOM_CADES_BES if zero.
+	// A signature of an enhanced type also passes CADESCOM_CADES_BES.
+	Type int
+}
+
+type VerificationResult struct {
+	// Valid is set if nmcades verified the message, Reason holds the error
+	// otherwise or the error that stopped the listing of the signers.
+	Valid    bool   `json:"valid"`
+	Reason   string `json:"reason,omitempty"`
+	Detached bool   `json:"detached"`
+	// ContentHash is the hex hash of the content computed with
+	// HashAlgorithm, the GOST R 34.11 variant of the key of the first signer.
+	ContentHash   string         `json:"content_hash,omitempty"`
+	HashAlgorithm int            `json:"hash_algorithm,omitempty"`
+	Signers       []SignerResult `json:"signers"`
+}
+
+type SignerResult struct {
+	Certificate *GostCertificate  `json:"certificate"`
+	X509        *x509.Certificate `json:"-"`
+	// SigningTime and TimeStampTime are zero if the signature has no such
+	// attribute.
+	SigningTime   time.Time `json:"signing_time"`
+	TimeStampTime time.Time `json:"timestamp_time"`
+	// Type is CADESCOM_CADES_BES, CADESCOM_CADES_T,
+	// CADESCOM_CADES_X_LONG_TYPE_1 or CADESCOM_PKCS7_TYPE.
+	Type       int  `json:"type"`
+	Valid      bool `json:"valid"`
+	ChainValid bool `json:"chain_valid"`
+	// Reason tells why the signature or its chain is not valid, or holds the
+	// error that stopped the check of the signer.
+	Reason string `json:"reason,omitempty"`
+}
+
+// Unsigned attributes that tell the CAdES type of a signature.
+const (
+	oidSignatureTimeStamp = "1.2.840.113549.1.9.16.2.14"
+	oidCertificateValues  = "1.2.840.113549.1.9.16.2.23"
+	oidRevocationValues   = "1.2.840.113549.1.9.16.2.24"
+)
+
+// gostHashAlgorithms maps the OID of a GOST key to its hash algorithm.
+var gostHashAlgorithms = map[string]int{
+	"1.2.643.2.2.19":    CADESCOM_HASH_ALGORITHM_CP_GOST_3411,
+	"1.2.643.7.1.1.1.1": CADESCOM_HASH_ALGORITHM_CP_GOST_3411_2012_256,
+	"1.2.643.7.1.1.1.2": CADESCOM_HASH_ALGORITHM_CP_GOST_3411_2012_512,
+}
+
+// Verify checks an attached or detached CAdES or PKCS#7 signature and reports
+// every signer. A signature that fails the check is not an error: the result
+// has Valid unset and the reason. Objects created for the check are
+// released. If ctx is done before nmcades answers, the session is closed,
+// see SendRequestContext.
+func (cades *Cades) Verify(ctx context.Context, request VerifyRequest) (*VerificationResult, error) {
+	result := &VerificationResult{}
+
+	content, attached, err := parseSignedMessage(request.Signature)
+	switch {
+	case err == nil:
+		result.Detached = !attached
+	case request.Data != nil || request.Reader != nil:
+		// BER encoded message, trust the caller
+		result.Detached = true
+	}
+
+	if result.Detached {
+		if content, err = verifyContent(&request); err != nil {
+			return &VerificationResult{}, err
+		}
+	}
+
+	cadesType := request.Type
+	if cadesType == 0 {
+		cadesType = CADESCOM_CADES_BES
+	}
+
+	err = cades.withScopeContext(ctx, func(scoped *Cades) error {
+		signedData, err := NewCadesSignedData(scoped)
+		if err != nil {
+			return err
+		}
+		if _, err := signedData.SetContentEncoding(CADESCOM_BASE64_TO_BINARY); err != nil {
+			return err
+		}
+		if result.Detached {
+			if _, err := signedData.SetContent(base64.StdEncoding.EncodeToString(content)); err != nil {
+				return err
+			}
+		}
+
+		signature := base64.StdEncoding.EncodeToString(request.Signature)
+		err = signedData.VerifyCades(signature, cadesType, result.Detached)
+		if err := verificationError(err); err != nil {
+			return err
+		}
+		result.Valid = err == nil
+		if err != nil {
+			result.Reason = err.Error()
+		}
+
+		if err := verifySigners(signedData, result); err != nil {
+			return err
+		}
+
+		if content == nil && !result.Detached {
+			value, err := signedData.Content()
+			if err != nil {
+				return err
+			}
+			if content, err = decodeBase64(value); err != nil {
+				return fmt.Errorf("decode content: %w", err)
+			}
+		}
+		return hashContent(scoped, result, content)
+	})
+	if err != nil {
+		return &VerificationResult{}, err
+	}
+
+	return result, nil
+}
+
+func verifyContent(request *VerifyRequest) ([]byte, error) {
+	if request.Reader == nil {
+		if request.Data == nil {
+			return nil, errors.New("verify: content of a detached signature is required")
+		}
+		return request.Data, nil
+	}
+
+	data, err := io.ReadAll(request.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("verify: read content: %w", err)
+	}
+	return data, nil
+}
+
+// verificationError returns err if it stops the check: a transport, session
+// or context error. Other errors, such as a failed check, a missing
+// attribute or a certificate that does not parse, are reported in the
+// result.
+func verificationError(err error) error {
+	var transportErr *TransportError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &transportErr),
+		errors.Is(err, ErrCadesClosed),
+		errors.Is(err, ErrProcessExited),
+		errors.Is(err, ErrStaleObject),
+		errors.Is(err, context.Canceled),
+		errors.Is(err, context.DeadlineExceeded):
+		return err
+	}
+	return nil
+}
+
+// verifySigners lists the signers, an error of a signer is recorded in its
+// Reason and the next signer is checked.
+func verifySigners(signedData *CadesSignedData, result *VerificationResult) error {
+	result.Signers = []SignerResult{}
+
+	signers, err := signedData.Signers()
+	if err != nil {
+		return result.fail("signers", err)
+	}
+	count, err := signers.Count()
+	if err != nil {
+		return result.fail("signers", err)
+	}
+
+	for i := uint16(1); i <= count; i++ {
+		signer := &SignerResult{}
+		item, err := signers.Item(i)
+		if err != nil {
+			err = signer.fail("signer", err)
+		} else {
+			err = verifySigner(item, signer)
+		}
+		if err != nil {
+			return err
+		}
+		result.Signers = append(result.Signers, *signer)
+	}
+	return nil
+}
+
+// fail records err in Reason unless it stops the check.
+func (result *VerificationResult) fail(step string, err error) error {
+	if err := verificationError(err); err != nil {
+		return err
+	}
+	if result.Reason == "" {
+		result.Reason = fmt.Sprintf("%s: %s", step, err)
+	}
+	return nil
+}
+
+// fail records err in Reason unless it stops the check.
+func (result *SignerResult) fail(step string, err error) error {
+	if err := verificationError(err); err != nil {
+		return err
+	}
+	result.Reason = fmt.Sprintf("%s: %s", step, err)
+	return nil
+}
+
+func verifySigner(signer *CPSigner, result *SignerResult) error {
+	certificate, err := signer.Certificate()
+	if err != nil {
+		return result.fail("certificate", err)
+	}
+	if result.X509, err = certificate.X509(); err != nil {
+		result.X509 = nil
+		return result.fail("certificate", err)
+	}
+	if gost, err := ParseGostCertificate(result.X509); err == nil {
+		result.Certificate = gost
+	}
+
+	// the attributes are optional
+	if result.SigningTime, err = signer.SigningTime(); verificationError(err) != nil {
+		return err
+	}
+	if result.TimeStampTime, err = signer.SignatureTimeStampTime(); verificationError(err) != nil {
+		return err
+	}
+	if result.Type, err = signatureType(signer); err != nil {
+		return result.fail("signature type", err)
+	}
+
+	status, err := signer.SignatureStatus()
+	if err != nil {
+		return result.fail("signature status", err)
+	}
+	if result.Valid, err = status.IsValid(); err != nil {
+		return result.fail("signature status", err)
+	}
+
+	chain, err := certificate.IsValid()
+	if err != nil {
+		return result.fail("certificate chain", err)
+	}
+	if result.ChainValid, err = chain.Result(); err != nil {
+		return result.fail("certificate chain", err)
+	}
+
+	switch {
+	case !result.Valid:
+		result.Reason = "signature is not valid"
+	case !result.ChainValid:
+		result.Reason = "certificate chain is not valid"
+	}
+	return nil
+}
+
+// signatureType tells the type by the attributes: PKCS#7 has no
+// authenticated ones, CAdES-T adds a signature time-stamp and
+// CAdES-X Long Type 1 the certificate and revocation values.
+func signatureType(signer *CPSigner) (int, error) {
+	authenticated, err := signer.AuthenticatedAttributes2()
+	if err != nil {
+		return 0, err
+	}
+	count, err := authenticated.Count()
+	if err != nil {
+		return 0, err
+	}
+	if count == 0 {
+		return CADESCOM_PKCS7_TYPE, nil
+	}
+
+	unauthenticated, err := signer.UnauthenticatedAttributes()
+	if err != nil {
+		return 0, err
+	}
+	count, err = unauthenticated.Count()
+	if err != nil {
+		return 0, err
+	}
+
+	oids := map[string]bool{}
+	for i := uint16(1); i <= count; i++ {
+		attribute, err := unauthenticated.Item(i)
+		if err != nil {
+			return 0, err
+		}
+		oid, err := attribute.OID()
+		if err != nil {
+			return 0, err
+		}
+		oids[oid] = true
+	}
+
+	switch {
+	case oids[oidCertificateValues] && oids[oidRevocationValues]:
+		return CADESCOM_CADES_X_LONG_TYPE_1, nil
+	case oids[oidSignatureTimeStamp]:
+		return CADESCOM_CADES_T, nil
+	}
+	return CADESCOM_CADES_BES, nil
+}
+
+func hashContent(cades *Cades, result *VerificationResult, content []byte) error {
+	if len(result.Signers) == 0 || result.Signers[0].Certificate == nil {
+		return nil
+	}
+	algorithm, ok := gostHashAlgorithms[result.Signers[0].Certificate.Algorithm.OID]
+	if !ok {
+		return nil
+	}
+
+	hashedData, err := NewHashedData(cades)
+	if err != nil {
+		return err
+	}
+	if _, err := hashedData.SetAlgorithm(algorithm); err != nil {
+		return err
+	}
+	if _, err := hashedData.SetDataEncoding(CADESCOM_BASE64_TO_BINARY); err != nil {
+		return err
+	}
+	if err := hashedData.Hash(base64.StdEncoding.EncodeToString(content)); err != nil {
+		return err
+	}
+
+	value, err := hashedData.Value()
+	if err != nil {
+		return err
+	}
+	result.ContentHash = value
+	result.HashAlgorithm = algorithm
+	return nil
+}
+
+type contentInfoAsn1 struct {
+	ContentType asn1.ObjectIdentifier
+	Content     signedDataAsn1 `asn1:"explicit,tag:0"`
+}
+
+type signedDataAsn1 struct {
+	Version          int
+	DigestAlgorithms asn1.RawValue `asn1:"set"`
+	EncapContentInfo encapContentInfoAsn1
+}
+
+type encapContentInfoAsn1 struct {
+	EContentType asn1.ObjectIdentifier
+	EContent     []byte `asn1:"explicit,optional,tag:0"`
+}
+
+// parseSignedMessage returns the encapsulated content of a DER encoded CMS
+// message and whether it is present.
+func parseSignedMessage(signature []byte) ([]byte, bool, error) {
+	var info contentInfoAsn1
+	if _, err := asn1.Unmarshal(signature, &info); err != nil {
+		return nil, false, err
+	}
+
+	content := info.Content.EncapContentInfo.EContent
+	return content, content != nil, nil
+}
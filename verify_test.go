@@ -0,0 +1,226 @@
+package cades
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testCertificate(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "signer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(der)
+}
+
+// signedDataClasses emulate the classes Verify uses. VerifyCades adds a
+// signer for each of the signers functions.
+func signedDataClasses(signers ...func(e *Emulator) (*EmulatorObject, error)) []*EmulatorClass {
+	return []*EmulatorClass{
+		{
+			Name:       "CAdESCOM.CadesSignedData",
+			Properties: map[string]any{"Content": "", "ContentEncoding": 0, "Signers": errors.New("not verified")},
+			Methods: map[string]EmulatorMethod{
+				"VerifyCades": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					collection, err := obj.Emulator.NewObject("CAdESCOM.CPSigners")
+					if err != nil {
+						return nil, err
+					}
+					var items []*EmulatorObject
+					for _, signer := range signers {
+						item, err := signer(obj.Emulator)
+						if err != nil {
+							return nil, err
+						}
+						items = append(items, item)
+					}
+					collection.Properties["Count"] = len(items)
+					collection.Properties["items"] = items
+					obj.Properties["Signers"] = collection
+					return nil, nil
+				},
+			},
+		},
+		{
+			Name:       "CAdESCOM.CPSigners",
+			Properties: map[string]any{"Count": 0},
+			Methods: map[string]EmulatorMethod{
+				"Item": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					items := obj.Properties["items"].([]*EmulatorObject)
+					index, _ := params[0].Value.(float64)
+					if index < 1 || int(index) > len(items) {
+						return nil, fmt.Errorf("invalid index %v", params[0].Value)
+					}
+					return items[int(index)-1], nil
+				},
+			},
+		},
+		{Name: "CAdESCOM.CPSigner"},
+		{Name: "CAdESCOM.CPAttributes", Properties: map[string]any{"Count": 0}},
+		{Name: "CAdESCOM.CPSignatureStatus", Properties: map[string]any{"IsValid": true}},
+		{Name: "CAdESCOM.CertificateStatus", Properties: map[string]any{"Result": true}},
+		{
+			Name: "CAdESCOM.Certificate",
+			Methods: map[string]EmulatorMethod{
+				"Export": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					return obj.Properties["der"], nil
+				},
+				"IsValid": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+					return obj.Emulator.NewObject("CAdESCOM.CertificateStatus")
+				},
+			},
+		},
+	}
+}
+
+// emulatedSigner returns a valid PKCS#7 signer with the certificate der.
+func emulatedSigner(der string) func(e *Emulator) (*EmulatorObject, error) {
+	return func(e *Emulator) (*EmulatorObject, error) {
+		signer, err := e.NewObject("CAdESCOM.CPSigner")
+		if err != nil {
+			return nil, err
+		}
+		certificate, err := e.NewObject("CAdESCOM.Certificate")
+		if err != nil {
+			return nil, err
+		}
+		certificate.Properties["der"] = der
+		attributes, err := e.NewObject("CAdESCOM.CPAttributes")
+		if err != nil {
+			return nil, err
+		}
+		status, err := e.NewObject("CAdESCOM.CPSignatureStatus")
+		if err != nil {
+			return nil, err
+		}
+
+		signer.Properties["Certificate"] = certificate
+		signer.Properties["AuthenticatedAttributes2"] = attributes
+		signer.Properties["SignatureStatus"] = status
+		return signer, nil
+	}
+}
+
+func TestVerifySignerErrors(t *testing.T) {
+	noCertificate := func(e *Emulator) (*EmulatorObject, error) {
+		signer, err := e.NewObject("CAdESCOM.CPSigner")
+		if err != nil {
+			return nil, err
+		}
+		signer.Properties["Certificate"] = errors.New("certificate is not found")
+		return signer, nil
+	}
+	cades, _ := newEmulatorCades(t, signedDataClasses(noCertificate, emulatedSigner("not base64"), emulatedSigner(testCertificate(t)))...)
+
+	result, err := cades.Verify(context.Background(), VerifyRequest{Signature: []byte("signature")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || len(result.Signers) != 3 {
+		t.Fatalf("Verify() = valid %t, %d signers, want valid, 3 signers", result.Valid, len(result.Signers))
+	}
+
+	if reason := result.Signers[0].Reason; !strings.HasPrefix(reason, "certificate: ") || !strings.Contains(reason, "certificate is not found") {
+		t.Errorf("Signers[0].Reason = %q", reason)
+	}
+	if signer := result.Signers[1]; !strings.HasPrefix(signer.Reason, "certificate: ") || signer.X509 != nil || signer.Valid {
+		t.Errorf("Signers[1] = %+v, want the error of X509", signer)
+	}
+	signer := result.Signers[2]
+	if !signer.Valid || !signer.ChainValid || signer.Reason != "" || signer.Type != CADESCOM_PKCS7_TYPE {
+		t.Errorf("Signers[2] = %+v, want a valid PKCS#7 signer", signer)
+	}
+	if signer.X509 == nil || signer.X509.Subject.CommonName != "signer" {
+		t.Errorf("Signers[2].X509 = %v", signer.X509)
+	}
+	if live := cades.LiveObjects(); live != 0 {
+		t.Errorf("LiveObjects() = %d, want 0", live)
+	}
+}
+
+func TestVerifySignersCount(t *testing.T) {
+	classes := signedDataClasses(emulatedSigner(testCertificate(t)))
+	verifyCades := classes[0].Methods["VerifyCades"]
+	classes[0].Methods["VerifyCades"] = func(obj *EmulatorObject, params []CadesParam) (any, error) {
+		if _, err := verifyCades(obj, params); err != nil {
+			return nil, err
+		}
+		signers := obj.Properties["Signers"].(*EmulatorObject)
+		signers.Properties["Count"] = errors.New("signers are not available")
+		return nil, nil
+	}
+	cades, _ := newEmulatorCades(t, classes...)
+
+	result, err := cades.Verify(context.Background(), VerifyRequest{Signature: []byte("signature")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Signers) != 0 || !strings.HasPrefix(result.Reason, "signers: ") {
+		t.Errorf("Verify() = %d signers, reason %q, want the error of Count", len(result.Signers), result.Reason)
+	}
+}
+
+func TestVerifyInterrupted(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	classes := signedDataClasses(emulatedSigner(testCertificate(t)))
+	classes[1].Methods["Item"] = func(obj *EmulatorObject, params []CadesParam) (any, error) {
+		<-release
+		return nil, errors.New("released")
+	}
+	cades, _ := newEmulatorCades(t, classes...)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := cades.Verify(ctx, VerifyRequest{Signature: []byte("signature")}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Verify() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestVerificationError(t *testing.T) {
+	tests := []struct {
+		err   error
+		fatal bool
+	}{
+		{nil, false},
+		{&NmcadesError{Message: "signature is not valid"}, false},
+		{ErrEmpty, false},
+		{base64.CorruptInputError(0), false},
+		{x509.CertificateInvalidError{}, false},
+		{&TransportError{Op: "receive response", Err: io.EOF}, true},
+		{&InterruptedError{Err: context.Canceled}, true},
+		{ErrCadesClosed, true},
+		{&ProcessExitError{ExitCode: 1}, true},
+		{fmt.Errorf("%w: objid 1", ErrStaleObject), true},
+		{context.DeadlineExceeded, true},
+	}
+
+	for _, test := range tests {
+		if got := verificationError(test.err); (got != nil) != test.fatal {
+			t.Errorf("verificationError(%v) = %v, fatal %t", test.err, got, test.fatal)
+		}
+	}
+}
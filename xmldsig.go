@@ -0,0 +1,25 @@
+package cades
+
+import "fmt"
+
+// xmlDsigMethods maps the OID of a GOST key to the XMLDSig signature and
+// digest method URIs.
+var xmlDsigMethods = map[string][2]string{
+	"1.2.643.2.2.19":    {XmlDsigGost3410Url, XmlDsigGost3411Url},
+	"1.2.643.7.1.1.1.1": {XmlDsigGost3410Url2012256, XmlDsigGost3411Url2012256},
+	"1.2.643.7.1.1.1.2": {XmlDsigGost3410Url2012512, XmlDsigGost3411Url2012512},
+}
+
+// XmlDsigMethods returns the SignatureMethod and DigestMethod URIs for the
+// GOST R 34.10-2001, 34.10-2012 256 or 512 bit key of the certificate:
+//
+//	signatureMethod, digestMethod, err := XmlDsigMethods(certificate)
+//	_, err = signedXML.SetSignatureMethod(signatureMethod)
+//	_, err = signedXML.SetDigestMethod(digestMethod)
+func XmlDsigMethods(certificate *GostCertificate) (string, string, error) {
+	methods, ok := xmlDsigMethods[certificate.Algorithm.OID]
+	if !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrUnknownAlgorithm, certificate.Algorithm.OID)
+	}
+	return methods[0], methods[1], nil
+}
@@ -0,0 +1,124 @@
+package cades
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestXmlDsigMethods(t *testing.T) {
+	tests := []struct {
+		oid             string
+		signatureMethod string
+		digestMethod    string
+		err             error
+	}{
+		{"1.2.643.2.2.19", XmlDsigGost3410Url, XmlDsigGost3411Url, nil},
+		{"1.2.643.7.1.1.1.1", XmlDsigGost3410Url2012256, XmlDsigGost3411Url2012256, nil},
+		{"1.2.643.7.1.1.1.2", XmlDsigGost3410Url2012512, XmlDsigGost3411Url2012512, nil},
+		{"1.2.840.10045.2.1", "", "", ErrUnknownAlgorithm},
+		{"", "", "", ErrUnknownAlgorithm},
+	}
+
+	for _, test := range tests {
+		certificate := &GostCertificate{Algorithm: AlgorithmInfo{OID: test.oid}}
+		signatureMethod, digestMethod, err := XmlDsigMethods(certificate)
+		if !errors.Is(err, test.err) {
+			t.Errorf("XmlDsigMethods(%q) error = %v, want %v", test.oid, err, test.err)
+		}
+		if signatureMethod != test.signatureMethod || digestMethod != test.digestMethod {
+			t.Errorf("XmlDsigMethods(%q) = %q, %q, want %q, %q", test.oid, signatureMethod, digestMethod, test.signatureMethod, test.digestMethod)
+		}
+	}
+}
+
+// signedXMLClass emulates CAdESCOM.SignedXML. Sign wraps the content into a
+// Signature element with the signature and digest methods, Verify accepts
+// only such a document.
+var signedXMLClass = &EmulatorClass{
+	Name: "CAdESCOM.SignedXML",
+	Properties: map[string]any{
+		"Content":         "",
+		"SignatureType":   0,
+		"SignatureMethod": "",
+		"DigestMethod":    "",
+		"Signers":         errors.New("document is not verified"),
+	},
+	Methods: map[string]EmulatorMethod{
+		"Sign": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+			if _, ok := obj.Emulator.paramValue(params[0]).(*EmulatorObject); !ok {
+				return nil, fmt.Errorf("invalid signer %v", params[0].Value)
+			}
+			return fmt.Sprintf(`%s<Signature type="%v" method="%s" digest="%s"/>`,
+				obj.Properties["Content"], obj.Properties["SignatureType"], obj.Properties["SignatureMethod"], obj.Properties["DigestMethod"]), nil
+		},
+		"Verify": func(obj *EmulatorObject, params []CadesParam) (any, error) {
+			if !strings.Contains(fmt.Sprint(params[0].Value), "<Signature ") {
+				return nil, errors.New("the signature is not valid (0x80090006)")
+			}
+			return nil, nil
+		},
+	},
+}
+
+func TestSignedXML(t *testing.T) {
+	cades, _ := newEmulatorCades(t, signedXMLClass, &EmulatorClass{Name: "CAdESCOM.CPSigner"})
+
+	certificate := &GostCertificate{Algorithm: AlgorithmInfo{OID: "1.2.643.7.1.1.1.1"}}
+	signatureMethod, digestMethod, err := XmlDsigMethods(certificate)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signedXML, err := NewSignedXML(cades)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signedXML.SetContent("<Document/>"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signedXML.SetSignatureType(CADESCOM_XML_SIGNATURE_TYPE_ENVELOPED); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signedXML.SetSignatureMethod(signatureMethod); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := signedXML.SetDigestMethod(digestMethod); err != nil {
+		t.Fatal(err)
+	}
+	if value, err := signedXML.SignatureMethod(); err != nil || value != XmlDsigGost3410Url2012256 {
+		t.Errorf("SignatureMethod() = %q, %v, want %q", value, err, XmlDsigGost3410Url2012256)
+	}
+	if value, err := signedXML.DigestMethod(); err != nil || value != XmlDsigGost3411Url2012256 {
+		t.Errorf("DigestMethod() = %q, %v, want %q", value, err, XmlDsigGost3411Url2012256)
+	}
+
+	signer, err := NewCPSigner(cades)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := signedXML.Sign(signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := fmt.Sprintf(`<Document/><Signature type="0" method="%s" digest="%s"/>`, XmlDsigGost3410Url2012256, XmlDsigGost3411Url2012256)
+	if signed != want {
+		t.Errorf("Sign() = %q, want %q", signed, want)
+	}
+
+	verifier, err := NewSignedXML(cades)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := verifier.Verify(signed); err != nil {
+		t.Errorf("Verify() of the signed document: %v", err)
+	}
+	var nmcadesErr *NmcadesError
+	if err := verifier.Verify("<Document/>"); !errors.As(err, &nmcadesErr) || nmcadesErr.Op != "method Verify" {
+		t.Errorf("Verify() of an unsigned document: error = %v, want *NmcadesError of method Verify", err)
+	}
+	if _, err := verifier.Signers(); !errors.As(err, new(*NmcadesError)) {
+		t.Errorf("Signers() error = %v, want *NmcadesError", err)
+	}
+}